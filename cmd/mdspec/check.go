@@ -0,0 +1,448 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/KEINOS/go-md-spec-check/mdspec"
+	"github.com/pkg/errors"
+)
+
+// waitDelayRenderer is the time to wait for the I/O of a renderer process after
+// it is killed on timeout.
+const waitDelayRenderer = time.Second
+
+// checkFlags holds the flags of the check command.
+type checkFlags struct {
+	version        string
+	examples       string
+	format         string
+	renderer       string
+	knownFailures  string
+	specFile       string
+	baseline       string
+	updateBaseline string
+	sections       []string
+	thresholds     []mdspec.Threshold
+	concurrency    int
+	timeout        time.Duration
+	normalize      bool
+}
+
+// ----------------------------------------------------------------------------
+//  Check command
+// ----------------------------------------------------------------------------
+
+// runCheck runs the check command and returns the exit code.
+func runCheck(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags, command, err := parseCheckFlags(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitPass
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+
+		return exitHarness
+	}
+
+	opts, err := flags.options()
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+
+		return exitHarness
+	}
+
+	baseline, err := loadBaseline(flags.baseline)
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+
+		return exitHarness
+	}
+
+	if baseline != nil {
+		opts = append(opts, mdspec.WithBaseline(baseline))
+	}
+
+	report, err := mdspec.RunSpecContext(ctx, flags.version, newRenderer(command), opts...)
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+
+		return exitHarness
+	}
+
+	switch flags.format {
+	case "summary":
+		err = writeSummary(stdout, report)
+	case "junit":
+		err = report.WriteJUnit(stdout)
+	case "json":
+		err = report.WriteJSON(stdout)
+	default:
+		err = writeText(stdout, report, baseline)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, "error: failed to write the report:", err)
+
+		return exitHarness
+	}
+
+	if flags.updateBaseline != "" {
+		if err := saveBaseline(flags.updateBaseline, report.Baseline()); err != nil {
+			fmt.Fprintln(stderr, "error:", err)
+
+			return exitHarness
+		}
+	}
+
+	if len(flags.thresholds) > 0 {
+		if err := report.CheckThresholds(flags.thresholds...); err != nil {
+			fmt.Fprintln(stderr, err)
+
+			return exitFail
+		}
+
+		return exitPass
+	}
+
+	if len(unexpectedFailures(report, baseline)) > 0 || len(report.UnexpectedPasses()) > 0 {
+		return exitFail
+	}
+
+	return exitPass
+}
+
+// parseCheckFlags parses the arguments of the check command and returns the
+// flags and the renderer command.
+func parseCheckFlags(args []string, stderr io.Writer) (*checkFlags, []string, error) {
+	flags := &checkFlags{}
+	flagSet := flag.NewFlagSet("check", flag.ContinueOnError)
+
+	flagSet.SetOutput(stderr)
+	flagSet.Usage = func() {
+		fmt.Fprint(flagSet.Output(), "Usage:\n  mdspec check [flags] -- <command> [args...]\n\nFlags:\n")
+		flagSet.PrintDefaults()
+	}
+
+	flagSet.StringVar(&flags.version, "version", "latest", `spec version or constraint to check against. e.g. "v0.30" or "v0.31.x"`)
+	flagSet.StringVar(&flags.specFile, "spec-file", "",
+		"JSON or spec.txt file of the test cases to check against instead of the embedded ones. Overrides -version")
+	flagSet.Func("section", "run only the examples in the section (repeatable)", func(section string) error {
+		flags.sections = append(flags.sections, section)
+
+		return nil
+	})
+	flagSet.StringVar(&flags.examples, "examples", "", `run only the examples. e.g. "1,5,10-20"`)
+	flagSet.IntVar(&flags.concurrency, "concurrency", 0, "max number of concurrent renderer processes. -1 runs sequentially")
+	flagSet.StringVar(&flags.format, "format", "text", `output format: "text", "summary", "junit" or "json"`)
+	flagSet.DurationVar(&flags.timeout, "timeout", 0, "timeout per example. e.g. 5s. 0 means no timeout")
+	flagSet.StringVar(&flags.renderer, "renderer", "", "name of the renderer recorded in the report. Defaults to the command")
+	flagSet.BoolVar(&flags.normalize, "normalize", false, "compare the HTML after normalization")
+	flagSet.StringVar(&flags.knownFailures, "known-failures", "", "file of the example numbers known to fail")
+	flagSet.StringVar(&flags.baseline, "baseline", "",
+		"baseline JSON file. Fails only if an example that passed in the baseline fails")
+	flagSet.Func("min-pass-rate", "pass if the overall pass rate is at least the percentage. e.g. 95",
+		func(value string) error {
+			return flags.addThreshold("", value)
+		})
+	flagSet.Func("min-section-pass-rate",
+		`pass if the pass rate of the section is at least the percentage. e.g. "Tabs=100" (repeatable)`,
+		func(value string) error {
+			section, rate, ok := strings.Cut(value, "=")
+			if !ok || section == "" {
+				return errors.Errorf("want <section>=<percentage>, got %q", value)
+			}
+
+			return flags.addThreshold(section, rate)
+		})
+	flagSet.StringVar(&flags.updateBaseline, "update-baseline", "", "write the passing examples to the baseline JSON file")
+
+	if err := flagSet.Parse(args); err != nil {
+		return nil, nil, err //nolint:wrapcheck // flag errors are already descriptive
+	}
+
+	switch flags.format {
+	case "text", "summary", "junit", "json":
+	default:
+		return nil, nil, errors.Errorf("unknown format: %q", flags.format)
+	}
+
+	command := flagSet.Args()
+	if len(command) == 0 {
+		return nil, nil, errors.New("missing renderer command. e.g. mdspec check -- cmark")
+	}
+
+	if _, err := exec.LookPath(command[0]); err != nil {
+		return nil, nil, errors.Wrap(err, "renderer command not found")
+	}
+
+	if flags.renderer == "" {
+		flags.renderer = strings.Join(command, " ")
+	}
+
+	return flags, command, nil
+}
+
+// options returns the options of mdspec for the flags.
+func (flags *checkFlags) options() ([]mdspec.Option, error) {
+	opts := []mdspec.Option{
+		mdspec.WithConcurrency(flags.concurrency),
+		mdspec.WithTimeout(flags.timeout),
+		mdspec.WithRenderer(flags.renderer),
+	}
+
+	if len(flags.sections) > 0 {
+		opts = append(opts, mdspec.WithSections(flags.sections...))
+	}
+
+	if flags.examples != "" {
+		exampleOpts, err := parseExamples(flags.examples)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, exampleOpts...)
+	}
+
+	if flags.normalize {
+		opts = append(opts, mdspec.WithNormalizedHTML())
+	}
+
+	if flags.specFile != "" {
+		suite, err := mdspec.LoadSuiteFile(flags.specFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid -spec-file")
+		}
+
+		opts = append(opts, mdspec.WithSuite(suite))
+	}
+
+	if flags.knownFailures != "" {
+		exampleNums, err := mdspec.LoadKnownFailures(flags.knownFailures)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid -known-failures")
+		}
+
+		opts = append(opts, mdspec.WithKnownFailures(exampleNums...))
+	}
+
+	return opts, nil
+}
+
+// addThreshold adds the minimum pass rate of the section, or the overall one if
+// the section is empty. The rate is a percentage such as "95" or "95%".
+func (flags *checkFlags) addThreshold(section, rate string) error {
+	const maxRate = 100
+
+	minPassRate, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(rate), "%"), 64)
+	if err != nil || minPassRate < 0 || minPassRate > maxRate {
+		return errors.Errorf("invalid percentage: %q", rate)
+	}
+
+	flags.thresholds = append(flags.thresholds, mdspec.Threshold{Section: section, MinPassRate: minPassRate})
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// loadBaseline loads the baseline file. It returns nil if the path is empty.
+func loadBaseline(pathFile string) (*mdspec.Baseline, error) {
+	if pathFile == "" {
+		return nil, nil //nolint:nilnil // no baseline is not an error
+	}
+
+	baseline, err := mdspec.LoadBaseline(pathFile)
+
+	return baseline, errors.Wrap(err, "invalid -baseline")
+}
+
+// saveBaseline writes the baseline to the file.
+func saveBaseline(pathFile string, baseline *mdspec.Baseline) error {
+	var buf bytes.Buffer
+
+	if err := baseline.WriteJSON(&buf); err != nil {
+		return errors.Wrap(err, "failed to encode baseline")
+	}
+
+	return errors.Wrap(os.WriteFile(pathFile, buf.Bytes(), 0o600), "failed to write baseline")
+}
+
+// unexpectedFailures returns the results that should fail the check. In the
+// regression mode, they are the regressions from the baseline.
+func unexpectedFailures(report *mdspec.Report, baseline *mdspec.Baseline) []mdspec.Result {
+	if baseline == nil {
+		return report.UnexpectedFailures()
+	}
+
+	failures := []mdspec.Result{}
+
+	for _, result := range report.Regressions(baseline) {
+		if !result.KnownFailure {
+			failures = append(failures, result)
+		}
+	}
+
+	return failures
+}
+
+// newRenderer returns a function that runs the command with the markdown as the
+// standard input and returns the standard output as the HTML. The process is
+// killed when the context is done.
+func newRenderer(command []string) func(context.Context, string) (string, error) {
+	return func(ctx context.Context, markdown string) (string, error) {
+		var stdout, stderr bytes.Buffer
+
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Stdin = strings.NewReader(markdown)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		cmd.WaitDelay = waitDelayRenderer
+
+		if err := cmd.Run(); err != nil {
+			msg := "renderer failed"
+			if errOutput := strings.TrimSpace(stderr.String()); errOutput != "" {
+				msg += ": " + errOutput
+			}
+
+			return stdout.String(), errors.Wrap(err, msg)
+		}
+
+		return stdout.String(), nil
+	}
+}
+
+// parseExamples parses the list of example numbers and ranges such as
+// "1,5,10-20" into options.
+func parseExamples(list string) ([]mdspec.Option, error) {
+	opts := []mdspec.Option{}
+
+	for item := range strings.SplitSeq(list, ",") {
+		item = strings.TrimSpace(item)
+
+		first, last, isRange := strings.Cut(item, "-")
+
+		numFirst, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid -examples: %q", item)
+		}
+
+		if !isRange {
+			opts = append(opts, mdspec.WithExamples(numFirst))
+
+			continue
+		}
+
+		numLast, err := strconv.Atoi(last)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid -examples: %q", item)
+		}
+
+		opts = append(opts, mdspec.WithExampleRange(numFirst, numLast))
+	}
+
+	return opts, nil
+}
+
+// writeText writes the details of the unexpected results followed by the
+// totals. In the regression mode, only the regressions are detailed and the
+// improvements are listed.
+func writeText(out io.Writer, report *mdspec.Report, baseline *mdspec.Baseline) error {
+	var buf bytes.Buffer
+
+	for _, result := range report.UnexpectedPasses() {
+		fmt.Fprintf(&buf, "--- UNEXPECTED PASS: example %d (%s)\n", result.TestCase.ExampleNum, result.TestCase.Section)
+		buf.WriteString("    listed as a known failure but passed\n")
+	}
+
+	for _, result := range unexpectedFailures(report, baseline) {
+		fmt.Fprintf(&buf, "--- %s: example %d (%s)\n",
+			strings.ToUpper(result.Status.String()), result.TestCase.ExampleNum, result.TestCase.Section)
+		buf.WriteString(indent(describeFailure(result)))
+	}
+
+	improvements := []mdspec.Result{}
+
+	if baseline != nil {
+		for _, result := range report.Improvements(baseline) {
+			if !result.KnownFailure {
+				improvements = append(improvements, result)
+			}
+		}
+	}
+
+	for _, result := range improvements {
+		fmt.Fprintf(&buf, "--- IMPROVEMENT: example %d (%s)\n", result.TestCase.ExampleNum, result.TestCase.Section)
+	}
+
+	fmt.Fprintf(&buf, "spec %s: %d/%d passed (%.1f%%), failed: %d, errored: %d, timed out: %d, panicked: %d",
+		report.Version, report.Passed, report.Total, report.PassRate(),
+		report.Failed, report.Errored, report.TimedOut, report.Panicked)
+
+	if baseline != nil {
+		fmt.Fprintf(&buf, ", regressions: %d, improvements: %d",
+			len(unexpectedFailures(report, baseline)), len(improvements))
+	}
+
+	buf.WriteString("\n")
+
+	_, err := buf.WriteTo(out)
+
+	return errors.Wrap(err, "failed to write text report")
+}
+
+// writeSummary writes the totals of each section as a table.
+func writeSummary(out io.Writer, report *mdspec.Report) error {
+	const padding = 2
+
+	table := tabwriter.NewWriter(out, 0, 0, padding, ' ', 0)
+
+	fmt.Fprintln(table, "SECTION\tPASSED\tTOTAL\tRATE")
+
+	for _, section := range report.Sections() {
+		fmt.Fprintf(table, "%s\t%d\t%d\t%.1f%%\n", section.Name, section.Passed, section.Total, section.PassRate())
+	}
+
+	fmt.Fprintf(table, "TOTAL (spec %s)\t%d\t%d\t%.1f%%\n",
+		report.Version, report.Passed, report.Total, report.PassRate())
+
+	return errors.Wrap(table.Flush(), "failed to write summary report")
+}
+
+// describeFailure returns the details of a failed result. Without an error
+// message, the name of the status is used instead.
+func describeFailure(result mdspec.Result) string {
+	if result.Status != mdspec.StatusFail {
+		if result.Err == nil || result.Err.Error() == "" {
+			return result.Status.String()
+		}
+
+		return result.Err.Error()
+	}
+
+	details := fmt.Sprintf("given markdown: %#v\n", result.TestCase.Markdown)
+	if result.Detail != "" {
+		details += result.Detail + "\n"
+	}
+
+	return details + result.Diff()
+}
+
+// indent indents each line of the text and ensures the trailing newline.
+func indent(text string) string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	return "    " + strings.Join(lines, "\n    ") + "\n"
+}
@@ -0,0 +1,140 @@
+/*
+Command mdspec checks if an external Markdown renderer complies with the
+CommonMark specification using the test cases embedded in the mdspec package.
+
+The renderer can be any executable that reads Markdown from the standard input
+and writes HTML to the standard output, such as "cmark" or "pandoc".
+
+Usage:
+
+	mdspec check [flags] -- <command> [args...]
+	mdspec versions
+	mdspec diff <old version> <new version>
+
+Examples:
+
+	mdspec check -- cmark
+	mdspec check -version v0.30 -section Tabs -section "ATX headings" -- pandoc -f commonmark -t html
+	mdspec check -format junit -- cmark > report.xml
+	mdspec check -format json -renderer "cmark 0.31.1" -- cmark > report.json
+	mdspec check -baseline baseline.json -- ./my-renderer
+	mdspec check -min-pass-rate 95 -min-section-pass-rate "Tabs=100" -- ./my-renderer
+	mdspec diff v0.30 v0.31.2
+
+Exit codes:
+
+	0: all the test cases passed
+	1: one or more test cases failed, regressed from the baseline or the pass
+	   rates are below the thresholds
+	2: the check could not be run, such as invalid flags or an unknown renderer
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/KEINOS/go-md-spec-check/mdspec"
+)
+
+// Exit codes of the command.
+const (
+	exitPass    = 0
+	exitFail    = 1
+	exitHarness = 2
+)
+
+// usage is the help message of the command.
+const usage = `Usage:
+  mdspec check [flags] -- <command> [args...]
+  mdspec versions
+  mdspec diff <old version> <new version>
+
+Commands:
+  check     run the CommonMark spec test cases against the renderer command
+  versions  list the available spec versions
+  diff      show the examples added, removed or modified between two spec versions
+
+Run "mdspec check -h" for the flags of check.
+`
+
+// ----------------------------------------------------------------------------
+//  Main
+// ----------------------------------------------------------------------------
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+
+	exitCode := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+
+	stop()
+	os.Exit(exitCode)
+}
+
+// run runs the command with the arguments without the program name and returns
+// the exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+
+		return exitHarness
+	}
+
+	switch args[0] {
+	case "check":
+		return runCheck(ctx, args[1:], stdout, stderr)
+	case "versions":
+		return runVersions(stdout, stderr)
+	case "diff":
+		return runDiff(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+
+		return exitPass
+	default:
+		fmt.Fprintf(stderr, "unknown command: %q\n\n%s", args[0], usage)
+
+		return exitHarness
+	}
+}
+
+// runDiff prints the changes of the examples between two spec versions.
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 2 { //nolint:mnd // old and new versions
+		fmt.Fprintf(stderr, "error: want 2 spec versions, got %d\n\n%s", len(args), usage)
+
+		return exitHarness
+	}
+
+	diff, err := mdspec.DiffVersions(args[0], args[1])
+	if err == nil {
+		err = diff.WriteText(stdout)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+
+		return exitHarness
+	}
+
+	return exitPass
+}
+
+// runVersions prints the available spec versions.
+func runVersions(stdout, stderr io.Writer) int {
+	versions, err := mdspec.ListVersion()
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+
+		return exitHarness
+	}
+
+	for _, version := range versions {
+		fmt.Fprintln(stdout, version)
+	}
+
+	return exitPass
+}
@@ -0,0 +1,676 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KEINOS/go-md-spec-check/mdspec"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain makes the test binary act as a renderer command if it is called as
+// "<test binary> renderer <mode>". See runRenderer.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 2 && os.Args[1] == "renderer" {
+		os.Exit(runRenderer(os.Args[2]))
+	}
+
+	os.Exit(m.Run())
+}
+
+// ----------------------------------------------------------------------------
+//  run()
+// ----------------------------------------------------------------------------
+
+func Test_run_help(t *testing.T) {
+	t.Parallel()
+
+	for _, arg := range []string{"help", "-h", "--help"} {
+		stdout, stderr, exitCode := runCommand(t, arg)
+
+		require.Equal(t, exitPass, exitCode, "help should exit with 0")
+		assert.Contains(t, stdout, "mdspec check [flags] -- <command> [args...]")
+		assert.Empty(t, stderr)
+	}
+}
+
+func Test_run_no_args(t *testing.T) {
+	t.Parallel()
+
+	stdout, stderr, exitCode := runCommand(t)
+
+	require.Equal(t, exitHarness, exitCode)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "Usage:")
+}
+
+func Test_run_unknown_command(t *testing.T) {
+	t.Parallel()
+
+	_, stderr, exitCode := runCommand(t, "unknown")
+
+	require.Equal(t, exitHarness, exitCode)
+	assert.Contains(t, stderr, `unknown command: "unknown"`)
+}
+
+func Test_run_versions(t *testing.T) {
+	t.Parallel()
+
+	stdout, _, exitCode := runCommand(t, "versions")
+
+	require.Equal(t, exitPass, exitCode)
+	assert.Contains(t, stdout, "0.13\n")
+	assert.Contains(t, stdout, "0.31.2\n")
+}
+
+func Test_run_diff(t *testing.T) {
+	t.Parallel()
+
+	stdout, stderr, exitCode := runCommand(t, "diff", "v0.27", "v0.28")
+
+	require.Equal(t, exitPass, exitCode, "stderr: %s", stderr)
+	assert.True(t, strings.HasPrefix(stdout, "spec v0.27 -> v0.28: 6 added, 4 removed, 4 modified, 614 unchanged\n"))
+	assert.Contains(t, stdout, "~ example 315 -> 90 (Code spans -> Fenced code blocks): modified\n")
+	assert.Contains(t, stdout, "+ example 317 (Code spans): added\n")
+	assert.Contains(t, stdout, "  --- v0.27\n  +++ v0.28\n")
+}
+
+func Test_run_diff_errors(t *testing.T) {
+	t.Parallel()
+
+	_, stderr, exitCode := runCommand(t, "diff", "v0.30")
+
+	require.Equal(t, exitHarness, exitCode)
+	assert.Contains(t, stderr, "error: want 2 spec versions, got 1")
+
+	_, stderr, exitCode = runCommand(t, "diff", "v0.30", "v0.99")
+
+	require.Equal(t, exitHarness, exitCode)
+	assert.Contains(t, stderr, "error: failed to load the new spec")
+}
+
+// ----------------------------------------------------------------------------
+//  check command
+// ----------------------------------------------------------------------------
+
+func Test_check_pass(t *testing.T) {
+	t.Parallel()
+
+	stdout, stderr, exitCode := runCommand(t,
+		"check", "-section", "Tabs", "--", os.Args[0], "renderer", "golden",
+	)
+
+	require.Equal(t, exitPass, exitCode, "stderr: %s", stderr)
+	assert.Equal(t,
+		"spec v0.31.2: 11/11 passed (100.0%), failed: 0, errored: 0, timed out: 0, panicked: 0\n",
+		stdout)
+}
+
+func Test_check_fail(t *testing.T) {
+	t.Parallel()
+
+	stdout, _, exitCode := runCommand(t,
+		"check", "-examples", "1,3-4", "-concurrency", "-1", "--", os.Args[0], "renderer", "bad",
+	)
+
+	require.Equal(t, exitFail, exitCode)
+	assert.Contains(t, stdout, "--- FAIL: example 1 (Tabs)\n")
+	assert.Contains(t, stdout, "    given markdown: \"\\tfoo\\tbaz\\t\\tbim\\n\"\n")
+	assert.Contains(t, stdout, "    +<p>bad</p>\n")
+	assert.Contains(t, stdout, "--- FAIL: example 3 (Tabs)\n")
+	assert.Contains(t, stdout, "--- FAIL: example 4 (Tabs)\n")
+	assert.NotContains(t, stdout, "example 2 ")
+	assert.Contains(t, stdout, "0/3 passed (0.0%), failed: 3,")
+}
+
+func Test_check_normalize(t *testing.T) {
+	t.Parallel()
+
+	stdout, _, exitCode := runCommand(t,
+		"check", "-examples", "1", "-normalize", "--", os.Args[0], "renderer", "bad",
+	)
+
+	require.Equal(t, exitFail, exitCode)
+	assert.Contains(t, stdout, "    normalized HTML differs:\n")
+}
+
+func Test_check_known_failures(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "known_failures.txt")
+	require.NoError(t, os.WriteFile(pathFile, []byte("1\n2\n"), 0o600))
+
+	t.Run("expected failures pass", func(t *testing.T) {
+		t.Parallel()
+
+		stdout, _, exitCode := runCommand(t,
+			"check", "-examples", "1-2", "-known-failures", pathFile, "--", os.Args[0], "renderer", "bad",
+		)
+
+		require.Equal(t, exitPass, exitCode)
+		assert.NotContains(t, stdout, "--- ")
+	})
+
+	t.Run("unexpected passes fail", func(t *testing.T) {
+		t.Parallel()
+
+		stdout, _, exitCode := runCommand(t,
+			"check", "-examples", "1-2", "-known-failures", pathFile, "--", os.Args[0], "renderer", "golden",
+		)
+
+		require.Equal(t, exitFail, exitCode)
+		assert.Contains(t, stdout, "--- UNEXPECTED PASS: example 1 (Tabs)\n")
+		assert.Contains(t, stdout, "--- UNEXPECTED PASS: example 2 (Tabs)\n")
+	})
+}
+
+func Test_check_renderer_error(t *testing.T) {
+	t.Parallel()
+
+	stdout, _, exitCode := runCommand(t,
+		"check", "-examples", "1", "--", os.Args[0], "renderer", "crash",
+	)
+
+	require.Equal(t, exitFail, exitCode)
+	assert.Contains(t, stdout, "--- ERROR: example 1 (Tabs)\n")
+	assert.Contains(t, stdout, "renderer failed: renderer crashed: exit status 3")
+	assert.Contains(t, stdout, "errored: 1,")
+}
+
+func Test_check_timeout(t *testing.T) {
+	t.Parallel()
+
+	stdout, _, exitCode := runCommand(t,
+		"check", "-examples", "1", "-timeout", "100ms", "--", os.Args[0], "renderer", "hang",
+	)
+
+	require.Equal(t, exitFail, exitCode)
+	assert.Contains(t, stdout, "--- TIMEOUT: example 1 (Tabs)\n")
+	assert.Contains(t, stdout, "timed out after 100ms")
+}
+
+func Test_check_summary(t *testing.T) {
+	t.Parallel()
+
+	stdout, _, exitCode := runCommand(t,
+		"check", "-format", "summary", "-section", "Tabs", "-section", "Precedence", "--",
+		os.Args[0], "renderer", "golden",
+	)
+
+	require.Equal(t, exitPass, exitCode)
+	assert.Equal(t, ""+
+		"SECTION               PASSED  TOTAL  RATE\n"+
+		"Tabs                  11      11     100.0%\n"+
+		"Precedence            1       1      100.0%\n"+
+		"TOTAL (spec v0.31.2)  12      12     100.0%\n",
+		stdout)
+}
+
+func Test_check_junit(t *testing.T) {
+	t.Parallel()
+
+	stdout, _, exitCode := runCommand(t,
+		"check", "-format", "junit", "-examples", "1-2", "--", os.Args[0], "renderer", "bad",
+	)
+
+	require.Equal(t, exitFail, exitCode, "the exit code should not depend on the format")
+	assert.True(t, strings.HasPrefix(stdout, xml.Header))
+	assert.Contains(t, stdout, `<testsuites name="CommonMark spec v0.31.2" tests="2" failures="2"`)
+	assert.Contains(t, stdout, `<testcase name="example_2" classname="Tabs">`)
+}
+
+func Test_check_json(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name           string
+		expectRenderer string
+		args           []string
+	}{
+		{
+			name:           "default renderer name",
+			args:           []string{"check", "-format", "json", "-examples", "1-2", "--", os.Args[0], "renderer", "golden"},
+			expectRenderer: os.Args[0] + " renderer golden",
+		},
+		{
+			name: "given renderer name",
+			args: []string{
+				"check", "-format", "json", "-examples", "1-2", "-renderer", "golden v1.0.0",
+				"--", os.Args[0], "renderer", "golden",
+			},
+			expectRenderer: "golden v1.0.0",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			stdout, _, exitCode := runCommand(t, test.args...)
+
+			require.Equal(t, exitPass, exitCode)
+
+			report, err := mdspec.ReadReport(strings.NewReader(stdout))
+			require.NoError(t, err)
+
+			assert.Equal(t, "v0.31.2", report.Version)
+			assert.Equal(t, test.expectRenderer, report.Renderer)
+			assert.Equal(t, 2, report.Passed)
+			assert.Len(t, report.Results, 2)
+		})
+	}
+}
+
+func Test_check_baseline(t *testing.T) {
+	t.Parallel()
+
+	dirTemp := t.TempDir()
+	pathBaseline := filepath.Join(dirTemp, "baseline.json")
+
+	// Record examples 1 and 2 as passing
+	stdout, _, exitCode := runCommand(t,
+		"check", "-examples", "1-2", "-update-baseline", pathBaseline, "--", os.Args[0], "renderer", "golden",
+	)
+	require.Equal(t, exitPass, exitCode, stdout)
+
+	baseline, err := mdspec.LoadBaseline(pathBaseline)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, baseline.Passing)
+	assert.Equal(t, os.Args[0]+" renderer golden", baseline.Renderer)
+
+	t.Run("regression fails", func(t *testing.T) {
+		t.Parallel()
+
+		stdout, _, exitCode := runCommand(t,
+			"check", "-examples", "1-3", "-baseline", pathBaseline, "--", os.Args[0], "renderer", "bad",
+		)
+
+		require.Equal(t, exitFail, exitCode)
+		assert.Contains(t, stdout, "--- FAIL: example 1 (Tabs)\n")
+		assert.Contains(t, stdout, "--- FAIL: example 2 (Tabs)\n")
+		assert.NotContains(t, stdout, "example 3 ", "failing in the baseline should not be reported")
+		assert.Contains(t, stdout, ", regressions: 2, improvements: 0\n")
+	})
+
+	t.Run("improvement passes", func(t *testing.T) {
+		t.Parallel()
+
+		stdout, _, exitCode := runCommand(t,
+			"check", "-examples", "1-3", "-baseline", pathBaseline, "--", os.Args[0], "renderer", "golden",
+		)
+
+		require.Equal(t, exitPass, exitCode)
+		assert.Contains(t, stdout, "--- IMPROVEMENT: example 3 (Tabs)\n")
+		assert.Contains(t, stdout, ", regressions: 0, improvements: 1\n")
+	})
+
+	t.Run("known failures take precedence", func(t *testing.T) {
+		t.Parallel()
+
+		pathKnown := filepath.Join(t.TempDir(), "known_failures.txt")
+		require.NoError(t, os.WriteFile(pathKnown, []byte("2\n3\n"), 0o600))
+
+		stdout, _, exitCode := runCommand(t,
+			"check", "-examples", "1-3", "-baseline", pathBaseline, "-known-failures", pathKnown,
+			"--", os.Args[0], "renderer", "bad",
+		)
+
+		require.Equal(t, exitFail, exitCode)
+		assert.Contains(t, stdout, ", regressions: 1, improvements: 0\n")
+	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		_, stderr, exitCode := runCommand(t,
+			"check", "-version", "v0.30", "-baseline", pathBaseline, "--", os.Args[0], "renderer", "golden",
+		)
+
+		require.Equal(t, exitHarness, exitCode)
+		assert.Contains(t, stderr, "baseline is for another spec version")
+	})
+
+	t.Run("missing baseline file", func(t *testing.T) {
+		t.Parallel()
+
+		_, stderr, exitCode := runCommand(t,
+			"check", "-baseline", filepath.Join(dirTemp, "missing.json"), "--", os.Args[0], "renderer", "golden",
+		)
+
+		require.Equal(t, exitHarness, exitCode)
+		assert.Contains(t, stderr, "error: invalid -baseline")
+	})
+
+	t.Run("unwritable baseline file", func(t *testing.T) {
+		t.Parallel()
+
+		_, stderr, exitCode := runCommand(t,
+			"check", "-examples", "1", "-update-baseline", filepath.Join(dirTemp, "missing", "baseline.json"),
+			"--", os.Args[0], "renderer", "golden",
+		)
+
+		require.Equal(t, exitHarness, exitCode)
+		assert.Contains(t, stderr, "error: failed to write baseline")
+	})
+}
+
+func Test_check_thresholds(t *testing.T) {
+	t.Parallel()
+
+	// Examples 1 and 2 fail, so Tabs passes 9 of 11 (81.8%)
+	for _, test := range []struct {
+		name       string
+		expectErr  string
+		thresholds []string
+		exitCode   int
+	}{
+		{
+			name:       "overall met",
+			thresholds: []string{"-min-pass-rate", "80"},
+			exitCode:   exitPass,
+		},
+		{
+			name:       "section met",
+			thresholds: []string{"-min-section-pass-rate", "Tabs=81.8%"},
+			exitCode:   exitPass,
+		},
+		{
+			name:       "overall missed",
+			thresholds: []string{"-min-pass-rate", "90", "-min-section-pass-rate", "Tabs=50"},
+			expectErr:  "1 pass rate threshold(s) not met:\n  overall: 81.8% (9/11) < 90.0%\n",
+			exitCode:   exitFail,
+		},
+		{
+			name:       "section missed",
+			thresholds: []string{"-min-section-pass-rate", "Tabs=100", "-min-section-pass-rate", "Tab=0"},
+			expectErr:  "  Tabs: 81.8% (9/11) < 100.0%\n  Tab: no test cases run, want >= 0.0%\n",
+			exitCode:   exitFail,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := append([]string{"check", "-section", "Tabs"}, test.thresholds...)
+			args = append(args, "--", os.Args[0], "renderer", "golden-except-1-2")
+
+			_, stderr, exitCode := runCommand(t, args...)
+
+			require.Equal(t, test.exitCode, exitCode, stderr)
+
+			if test.expectErr == "" {
+				assert.Empty(t, stderr)
+
+				return
+			}
+
+			assert.Contains(t, stderr, test.expectErr)
+		})
+	}
+}
+
+func Test_check_spec_file(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "dialect.json")
+	require.NoError(t, os.WriteFile(pathFile, []byte(`[
+		{"markdown": "bad\n", "html": "<p>bad</p>\n", "example": 1, "section": "Bad"},
+		{"markdown": "good\n", "html": "<p>good</p>\n", "example": 2, "section": "Good"}
+	]`), 0o600))
+
+	stdout, _, exitCode := runCommand(t,
+		"check", "-spec-file", pathFile, "-version", "v0.13", "--", os.Args[0], "renderer", "bad",
+	)
+
+	require.Equal(t, exitFail, exitCode)
+	assert.Contains(t, stdout, "--- FAIL: example 2 (Good)\n")
+	assert.Contains(t, stdout, "spec dialect: 1/2 passed (50.0%)")
+
+	stdout, _, exitCode = runCommand(t,
+		"check", "-spec-file", pathFile, "-section", "Bad", "--", os.Args[0], "renderer", "bad",
+	)
+
+	require.Equal(t, exitPass, exitCode)
+	assert.Contains(t, stdout, "spec dialect: 1/1 passed (100.0%)")
+}
+
+func Test_check_spec_file_text(t *testing.T) {
+	t.Parallel()
+
+	fence := strings.Repeat("`", 32)
+	pathFile := filepath.Join(t.TempDir(), "spec.txt")
+	require.NoError(t, os.WriteFile(pathFile, []byte("# Bad\n\n"+
+		fence+" example\nbad\n.\n<p>bad</p>\n"+fence+"\n"), 0o600))
+
+	stdout, _, exitCode := runCommand(t,
+		"check", "-spec-file", pathFile, "--", os.Args[0], "renderer", "bad",
+	)
+
+	require.Equal(t, exitPass, exitCode)
+	assert.Contains(t, stdout, "spec spec: 1/1 passed (100.0%)")
+}
+
+func Test_check_help(t *testing.T) {
+	t.Parallel()
+
+	stdout, stderr, exitCode := runCommand(t, "check", "-h")
+
+	require.Equal(t, exitPass, exitCode)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "-known-failures")
+}
+
+func Test_check_harness_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name      string
+		expectErr string
+		args      []string
+	}{
+		{
+			name:      "undefined flag",
+			args:      []string{"check", "-unknown"},
+			expectErr: "flag provided but not defined: -unknown",
+		},
+		{
+			name:      "unknown format",
+			args:      []string{"check", "-format", "xml", "--", os.Args[0]},
+			expectErr: `error: unknown format: "xml"`,
+		},
+		{
+			name:      "missing command",
+			args:      []string{"check", "-section", "Tabs"},
+			expectErr: "error: missing renderer command",
+		},
+		{
+			name:      "command not found",
+			args:      []string{"check", "--", "mdspec-no-such-renderer"},
+			expectErr: "error: renderer command not found",
+		},
+		{
+			name:      "invalid example number",
+			args:      []string{"check", "-examples", "1,x", "--", os.Args[0]},
+			expectErr: `error: invalid -examples: "x"`,
+		},
+		{
+			name:      "invalid example range",
+			args:      []string{"check", "-examples", "1-x", "--", os.Args[0]},
+			expectErr: `error: invalid -examples: "1-x"`,
+		},
+		{
+			name:      "invalid pass rate",
+			args:      []string{"check", "-min-pass-rate", "101", "--", os.Args[0]},
+			expectErr: `invalid percentage: "101"`,
+		},
+		{
+			name:      "invalid section pass rate",
+			args:      []string{"check", "-min-section-pass-rate", "Tabs", "--", os.Args[0]},
+			expectErr: `want <section>=<percentage>, got "Tabs"`,
+		},
+		{
+			name:      "missing spec file",
+			args:      []string{"check", "-spec-file", "no-such-file.json", "--", os.Args[0]},
+			expectErr: "error: invalid -spec-file",
+		},
+		{
+			name:      "missing known failures file",
+			args:      []string{"check", "-known-failures", "no-such-file.txt", "--", os.Args[0]},
+			expectErr: "error: invalid -known-failures",
+		},
+		{
+			name:      "unknown version",
+			args:      []string{"check", "-version", "v0.1", "--", os.Args[0]},
+			expectErr: "error: ",
+		},
+		{
+			name:      "no test cases",
+			args:      []string{"check", "-section", "No such section", "--", os.Args[0]},
+			expectErr: "error: ",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			stdout, stderr, exitCode := runCommand(t, test.args...)
+
+			require.Equal(t, exitHarness, exitCode)
+			assert.Empty(t, stdout)
+			assert.Contains(t, stderr, test.expectErr)
+		})
+	}
+}
+
+func Test_check_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	exitCode := run(ctx, []string{"check", "--", os.Args[0], "renderer", "golden"}, &stdout, &stderr)
+
+	require.Equal(t, exitHarness, exitCode)
+	assert.Contains(t, stderr.String(), "spec run canceled")
+}
+
+func Test_writeText_write_error(t *testing.T) {
+	t.Parallel()
+
+	err := writeText(failWriter{}, &mdspec.Report{}, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write text report")
+}
+
+func Test_describeFailure_no_error(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "error", describeFailure(mdspec.Result{Status: mdspec.StatusError}))
+	assert.Equal(t, "timeout", describeFailure(mdspec.Result{Status: mdspec.StatusTimeout, Err: errors.New("")}))
+}
+
+// ----------------------------------------------------------------------------
+//  Helper functions
+// ----------------------------------------------------------------------------
+
+// failWriter is an io.Writer that always fails.
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+// runCommand runs the command with the arguments and returns the outputs and
+// the exit code.
+func runCommand(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	exitCode := run(t.Context(), args, &stdout, &stderr)
+
+	return stdout.String(), stderr.String(), exitCode
+}
+
+// runRenderer reads the markdown from stdin and writes the HTML to stdout as a
+// renderer command does. The mode is one of:
+//
+//   - "golden": writes the expected HTML of the example in spec v0.31.2.
+//   - "golden-except-1-2": same as "golden" except for the examples 1 and 2.
+//   - "bad": writes "<p>bad</p>\n" for any markdown.
+//   - "crash": writes a message to stderr and exits with 3.
+//   - "hang": never returns in time.
+func runRenderer(mode string) int {
+	markdown, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return 1
+	}
+
+	switch mode {
+	case "golden":
+		html, err := goldenHTML(string(markdown))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+
+			return 1
+		}
+
+		fmt.Fprint(os.Stdout, html)
+	case "bad":
+		fmt.Fprint(os.Stdout, "<p>bad</p>\n")
+	case "golden-except-1-2":
+		html, err := goldenHTML(string(markdown))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+
+			return 1
+		}
+
+		if strings.HasSuffix(string(markdown), "\tfoo\tbaz\t\tbim\n") {
+			html = "<p>bad</p>\n"
+		}
+
+		fmt.Fprint(os.Stdout, html)
+	case "crash":
+		fmt.Fprintln(os.Stderr, "renderer crashed")
+
+		return 3
+	case "hang":
+		time.Sleep(time.Minute)
+	}
+
+	return 0
+}
+
+// goldenHTML returns the expected HTML of the markdown in spec v0.31.2.
+func goldenHTML(markdown string) (string, error) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "mdspec", "_specs", "spec_v0.31.2.json"))
+	if err != nil {
+		return "", err
+	}
+
+	var testCases []mdspec.TestCase
+	if err := json.Unmarshal(data, &testCases); err != nil {
+		return "", err
+	}
+
+	for _, testCase := range testCases {
+		if testCase.Markdown == markdown {
+			return testCase.HTML, nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,176 @@
+package mdspec
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// Baseline is a snapshot of the examples that passed in a spec run. Create one
+// from a report with (*Report).Baseline and give it to WithBaseline to fail
+// only on regressions.
+type Baseline struct {
+	// Version is the spec version of the run. Example numbers differ between
+	// spec versions.
+	Version string `json:"version"`
+	// Renderer is the name of the renderer of the run. See WithRenderer.
+	Renderer string `json:"renderer,omitempty"`
+	// Passing is the sorted list of the example numbers that passed.
+	Passing []int `json:"passing"`
+}
+
+// WithBaseline enables the regression mode, in which only the examples that
+// passed in the baseline must pass. The failures of the other examples are
+// ignored and their passes are improvements, which can be listed with
+// (*Report).Improvements.
+//
+// The spec version of the run must match the one of the baseline, otherwise an
+// error wrapping ErrBaselineVersion is returned. Examples in WithKnownFailures
+// are treated as known failures as usual.
+//
+// Usage:
+//
+//	baseline, err := mdspec.LoadBaseline("testdata/baseline.json")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	// Fails only if an example that passed in the baseline fails now
+//	err = mdspec.SpecCheck(baseline.Version, myFunc, mdspec.WithBaseline(baseline))
+func WithBaseline(baseline *Baseline) Option {
+	return func(conf *config) {
+		conf.baseline = baseline
+	}
+}
+
+// Baseline returns the snapshot of the examples that passed in the report.
+//
+// Usage:
+//
+//	report, err := mdspec.RunSpec("latest", myFunc)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	err = report.Baseline().WriteJSON(file)
+func (r *Report) Baseline() *Baseline {
+	baseline := &Baseline{
+		Version:  r.Version,
+		Renderer: r.Renderer,
+		Passing:  []int{},
+	}
+
+	for _, result := range r.Results {
+		if result.Passed() {
+			baseline.Passing = append(baseline.Passing, result.TestCase.ExampleNum)
+		}
+	}
+
+	slices.Sort(baseline.Passing)
+
+	return baseline
+}
+
+// Regressions returns the results of the test cases that passed in the baseline
+// but did not pass in the report.
+func (r *Report) Regressions(baseline *Baseline) []Result {
+	regressions := []Result{}
+
+	for _, result := range r.Results {
+		if baseline.passes(result.TestCase) && !result.Passed() {
+			regressions = append(regressions, result)
+		}
+	}
+
+	return regressions
+}
+
+// Improvements returns the results of the test cases that did not pass in the
+// baseline but passed in the report.
+func (r *Report) Improvements(baseline *Baseline) []Result {
+	improvements := []Result{}
+
+	for _, result := range r.Results {
+		if !baseline.passes(result.TestCase) && result.Passed() {
+			improvements = append(improvements, result)
+		}
+	}
+
+	return improvements
+}
+
+// WriteJSON writes the baseline to "w" as indented JSON. E.g.
+//
+//	{
+//	  "version": "v0.31.2",
+//	  "renderer": "my-parser v1.2.0",
+//	  "passing": [1, 2, 3]
+//	}
+func (b *Baseline) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return errors.Wrap(encoder.Encode(b), "failed to write baseline")
+}
+
+// ReadBaseline reads a baseline written by (*Baseline).WriteJSON.
+func ReadBaseline(r io.Reader) (*Baseline, error) {
+	baseline := &Baseline{}
+
+	if err := json.NewDecoder(r).Decode(baseline); err != nil {
+		return nil, errors.Wrap(err, "failed to read baseline")
+	}
+
+	return baseline, nil
+}
+
+// LoadBaseline reads a baseline from the JSON file written by
+// (*Baseline).WriteJSON.
+func LoadBaseline(pathFile string) (*Baseline, error) {
+	file, err := os.Open(pathFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open baseline file")
+	}
+
+	defer file.Close()
+
+	baseline, err := ReadBaseline(file)
+	if err != nil {
+		return nil, errors.Wrap(err, pathFile)
+	}
+
+	return baseline, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// passes returns true if the example of the test case passed in the baseline.
+// A nil baseline has no passing examples.
+func (b *Baseline) passes(testCase TestCase) bool {
+	if b == nil {
+		return false
+	}
+
+	return slices.Contains(b.Passing, testCase.ExampleNum)
+}
+
+// checkBaseline returns an error if the baseline of the config is for another
+// spec version than the resolved one.
+func (conf *config) checkBaseline(resolvedVer string) error {
+	if conf.baseline == nil || conf.baseline.Version == "" || conf.baseline.Version == resolvedVer {
+		return nil
+	}
+
+	return errors.Wrapf(ErrBaselineVersion, "baseline %s, run %s", conf.baseline.Version, resolvedVer)
+}
+
+// isBaselineFailure returns true if the regression mode is enabled and the
+// example of the test case did not pass in the baseline.
+func (conf *config) isBaselineFailure(testCase TestCase) bool {
+	return conf.baseline != nil && !conf.baseline.passes(testCase)
+}
@@ -0,0 +1,232 @@
+package mdspec
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  (*Report).Baseline()
+// ----------------------------------------------------------------------------
+
+func TestReport_Baseline(t *testing.T) {
+	t.Parallel()
+
+	report, err := RunSpec("v0.31.2", passOnly(t, 5, 1, 3),
+		WithExampleRange(1, 6),
+		WithRenderer("my-parser v1.0.0"),
+	)
+	require.NoError(t, err)
+
+	baseline := report.Baseline()
+
+	assert.Equal(t, &Baseline{
+		Version:  "v0.31.2",
+		Renderer: "my-parser v1.0.0",
+		Passing:  []int{1, 3, 5},
+	}, baseline, "passing examples should be sorted")
+
+	var buf bytes.Buffer
+
+	require.NoError(t, baseline.WriteJSON(&buf))
+	assert.Contains(t, buf.String(), `"passing": [`)
+
+	readBaseline, err := ReadBaseline(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, baseline, readBaseline)
+}
+
+func TestReport_Baseline_no_pass(t *testing.T) {
+	t.Parallel()
+
+	baseline := (&Report{Version: "v0.31.2"}).Baseline()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, baseline.WriteJSON(&buf))
+	assert.Contains(t, buf.String(), `"passing": []`, "it should not be null")
+}
+
+// ----------------------------------------------------------------------------
+//  Regressions() and Improvements()
+// ----------------------------------------------------------------------------
+
+func TestReport_Regressions_Improvements(t *testing.T) {
+	t.Parallel()
+
+	baseline := &Baseline{Version: "v0.31.2", Passing: []int{1, 3, 5}}
+
+	report, err := RunSpec("v0.31.2", passOnly(t, 1, 2, 5), WithExampleRange(1, 6))
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{3}, exampleNums(report.Regressions(baseline)))
+	assert.Equal(t, []int{2}, exampleNums(report.Improvements(baseline)))
+
+	// A nil baseline has no passing examples
+	assert.Empty(t, report.Regressions(nil))
+	assert.Equal(t, []int{1, 2, 5}, exampleNums(report.Improvements(nil)))
+}
+
+// ----------------------------------------------------------------------------
+//  WithBaseline()
+// ----------------------------------------------------------------------------
+
+func TestWithBaseline(t *testing.T) {
+	t.Parallel()
+
+	baseline := &Baseline{Version: "v0.31.2", Passing: []int{1, 3, 5}}
+
+	for _, maxConcurrency := range []int{-1, 0} {
+		// Improved on 2 and 6, and still failing on 4
+		err := SpecCheck("v0.31.2", passOnly(t, 1, 2, 3, 5, 6),
+			WithExampleRange(1, 6),
+			WithBaseline(baseline),
+			WithConcurrency(maxConcurrency),
+		)
+		require.NoError(t, err, "failures of the examples not in the baseline should be ignored")
+
+		// Regressed on 3
+		err = SpecCheck("v0.31.2", passOnly(t, 1, 2, 5),
+			WithExampleRange(1, 6),
+			WithBaseline(baseline),
+			WithConcurrency(maxConcurrency),
+		)
+		require.Error(t, err, "regression should be an error")
+
+		var mismatchErr *MismatchError
+
+		require.ErrorAs(t, err, &mismatchErr)
+		assert.Equal(t, 3, mismatchErr.TestCase.ExampleNum)
+		assert.NotContains(t, err.Error(), "error 4_Tabs", "failing in the baseline should be ignored")
+	}
+}
+
+func TestWithBaseline_known_failures(t *testing.T) {
+	t.Parallel()
+
+	baseline := &Baseline{Version: "v0.31.2", Passing: []int{1}}
+
+	err := SpecCheck("v0.31.2", passOnly(t, 1, 2),
+		WithExampleRange(1, 2),
+		WithBaseline(baseline),
+		WithKnownFailures(2),
+	)
+
+	var passErr *UnexpectedPassError
+
+	require.ErrorAs(t, err, &passErr, "known failures should take precedence")
+	assert.Equal(t, 2, passErr.TestCase.ExampleNum)
+}
+
+func TestWithBaseline_version_mismatch(t *testing.T) {
+	t.Parallel()
+
+	baseline := &Baseline{Version: "v0.30", Passing: []int{1}}
+	myFunc := passOnly(t)
+
+	err := SpecCheck("v0.31.2", myFunc, WithBaseline(baseline))
+	require.ErrorIs(t, err, ErrBaselineVersion)
+	assert.Contains(t, err.Error(), "baseline v0.30, run v0.31.2")
+
+	_, err = RunSpec("latest", myFunc, WithBaseline(baseline))
+	require.ErrorIs(t, err, ErrBaselineVersion, "latest should be resolved before the check")
+
+	// A baseline without version is not checked
+	err = SpecCheck("v0.31.2", passOnly(t, 1), WithExamples(1), WithBaseline(&Baseline{Passing: []int{1}}))
+	require.NoError(t, err)
+}
+
+func TestRunT_with_baseline(t *testing.T) {
+	baseline := &Baseline{Version: "v0.31.2", Passing: []int{1, 3}}
+
+	RunT(t, "v0.31.2", passOnly(t, 1, 2, 3), WithExampleRange(1, 4), WithBaseline(baseline))
+}
+
+// ----------------------------------------------------------------------------
+//  LoadBaseline() and ReadBaseline()
+// ----------------------------------------------------------------------------
+
+func TestLoadBaseline(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, os.WriteFile(pathFile, []byte(`{"version":"v0.31.2","passing":[1,2]}`), 0o600))
+
+	baseline, err := LoadBaseline(pathFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, &Baseline{Version: "v0.31.2", Passing: []int{1, 2}}, baseline)
+}
+
+func TestLoadBaseline_errors(t *testing.T) {
+	t.Parallel()
+
+	dirTemp := t.TempDir()
+	pathMalformed := filepath.Join(dirTemp, "malformed.json")
+	require.NoError(t, os.WriteFile(pathMalformed, []byte(`{"passing":"1"}`), 0o600))
+
+	_, err := LoadBaseline(filepath.Join(dirTemp, "missing.json"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open baseline file")
+
+	_, err = LoadBaseline(pathMalformed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "malformed.json: failed to read baseline")
+
+	_, err = ReadBaseline(strings.NewReader(""))
+	require.Error(t, err)
+}
+
+func TestBaseline_WriteJSON_write_error(t *testing.T) {
+	t.Parallel()
+
+	err := (&Baseline{}).WriteJSON(&limitedWriter{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write baseline")
+}
+
+// ----------------------------------------------------------------------------
+//  Helper functions
+// ----------------------------------------------------------------------------
+
+// passOnly returns a function that returns the expected HTML of spec v0.31.2
+// only for the given example numbers and a wrong HTML for the others.
+func passOnly(t *testing.T, passingNums ...int) func(string) (string, error) {
+	t.Helper()
+
+	testCases, _ := prepareTestCasesMap(t, "spec_v0.31.2.json")
+	expected := map[string]string{}
+
+	for _, testCase := range testCases {
+		if slices.Contains(passingNums, testCase.ExampleNum) {
+			expected[testCase.Markdown] = testCase.HTML
+		}
+	}
+
+	return func(markdown string) (string, error) {
+		if html, ok := expected[markdown]; ok {
+			return html, nil
+		}
+
+		return "<p>bad</p>\n", nil
+	}
+}
+
+// exampleNums returns the example numbers of the results.
+func exampleNums(results []Result) []int {
+	nums := []int{}
+
+	for _, result := range results {
+		nums = append(nums, result.TestCase.ExampleNum)
+	}
+
+	return nums
+}
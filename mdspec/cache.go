@@ -0,0 +1,51 @@
+package mdspec
+
+import (
+	"slices"
+	"sync"
+)
+
+// The embedded spec files never change, so they are parsed once and shared by
+// all the goroutines. The callers get copies so that they cannot modify the
+// cached values of the others.
+var (
+	// cachedSpecInfos returns the list of the spec versions read once.
+	cachedSpecInfos = sync.OnceValues(readSpecInfos)
+	// testCasesLoaders holds the *testCasesLoader of each spec version.
+	testCasesLoaders sync.Map
+	// embeddedSuites holds the test cases shared by the suites of each spec
+	// version. See LoadSuite.
+	embeddedSuites sync.Map
+)
+
+// testCasesLoader reads the test cases of a spec version once.
+type testCasesLoader struct {
+	load func() ([]TestCase, error)
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// cachedTestCases returns a copy of the test cases of the resolved spec version.
+// The test cases are read once per version. Errors are not cached, so that
+// invalid versions do not stay in the cache.
+func cachedTestCases(specVersion string) ([]TestCase, error) {
+	newLoader := &testCasesLoader{
+		load: sync.OnceValues(func() ([]TestCase, error) {
+			return readTestCases(specVersion)
+		}),
+	}
+
+	cached, _ := testCasesLoaders.LoadOrStore(specVersion, newLoader)
+	loader, _ := cached.(*testCasesLoader)
+
+	testCases, err := loader.load()
+	if err != nil {
+		testCasesLoaders.CompareAndDelete(specVersion, loader)
+
+		return nil, err
+	}
+
+	return slices.Clone(testCases), nil
+}
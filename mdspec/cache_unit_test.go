@@ -0,0 +1,121 @@
+package mdspec
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSpecFS replaces the embedded spec files with the given ones during the
+// test. The caches are cleared before and after the test.
+func mockSpecFS(t *testing.T, files fstest.MapFS) {
+	t.Helper()
+
+	resetCaches(t)
+
+	oldSpecFS := specFS
+
+	t.Cleanup(func() {
+		specFS = oldSpecFS
+	})
+
+	specFS = files
+}
+
+// resetCaches clears the caches of the embedded spec files before and after the
+// test. Use it in the tests that mock the embedded files or their parsing.
+func resetCaches(t *testing.T) {
+	t.Helper()
+
+	reset := func() {
+		cachedSpecInfos = sync.OnceValues(readSpecInfos)
+		testCasesLoaders.Clear()
+		embeddedSuites.Clear()
+	}
+
+	reset()
+	t.Cleanup(reset)
+}
+
+// ----------------------------------------------------------------------------
+//  Concurrent access to the caches
+// ----------------------------------------------------------------------------
+
+// Run with the "-race" flag to detect data races on the first calls.
+//
+//nolint:paralleltest // do not parallelize to start from the empty caches
+func TestCaches_concurrent_first_calls(t *testing.T) {
+	resetCaches(t)
+
+	expectVersions, err := readSpecInfos()
+	require.NoError(t, err)
+
+	expectCases, err := readTestCases("v0.31.2")
+	require.NoError(t, err)
+
+	const numGoroutines = 16
+
+	var waitGroup sync.WaitGroup
+
+	for range numGoroutines {
+		waitGroup.Go(func() {
+			versions, err := ListVersion()
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			specInfos, err := Versions()
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			testCases, err := Cases("v0.31.2")
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			suite, err := LoadSuite("v0.31.2")
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			// Modify the returned values, which must not affect the others
+			slices.Reverse(versions)
+			specInfos[0].Version = "modified"
+			testCases[0].HTML = "modified"
+			suite.Cases()[0].HTML = "modified"
+
+			assert.NoError(t, SpecCheck("latest", func(string) (string, error) {
+				return "", nil
+			}, WithExamples(1), WithKnownFailures(1)))
+		})
+	}
+
+	waitGroup.Wait()
+
+	specInfos, err := Versions()
+	require.NoError(t, err)
+	assert.Equal(t, expectVersions, specInfos, "the cached versions should not be modified")
+
+	testCases, err := Cases("v0.31.2")
+	require.NoError(t, err)
+	assert.Equal(t, expectCases, testCases, "the cached test cases should not be modified")
+
+	suite, err := LoadSuite("v0.31.2")
+	require.NoError(t, err)
+	assert.Equal(t, expectCases, suite.Cases(), "the cached suite should not be modified")
+}
+
+func Test_cachedTestCases_error_not_cached(t *testing.T) {
+	t.Parallel()
+
+	_, err := cachedTestCases("v0.1")
+	require.ErrorIs(t, err, ErrSpecNotFound)
+
+	_, cached := testCasesLoaders.Load("v0.1")
+	assert.False(t, cached, "failed loads should be removed from the cache")
+}
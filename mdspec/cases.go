@@ -0,0 +1,57 @@
+package mdspec
+
+import "github.com/pkg/errors"
+
+// Cases returns all the test cases of the spec version in spec order. "latest"
+// is resolved to the latest available version.
+//
+// The returned slice is a copy, so modifying it does not affect the test cases
+// used by the other functions.
+//
+// Usage:
+//
+//	testCases, err := mdspec.Cases("v0.31.2")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	for _, testCase := range testCases {
+//		f.Add(testCase.Markdown) // fuzz seeds
+//	}
+func Cases(specVersion string) ([]TestCase, error) {
+	return loadTestCases(specVersion)
+}
+
+// Case returns the test case of the example number in the spec version. If there
+// is no such example, the error wraps ErrExampleNotFound.
+func Case(specVersion string, exampleNum int) (TestCase, error) {
+	testCases, err := loadTestCases(specVersion)
+	if err != nil {
+		return TestCase{}, err
+	}
+
+	for _, testCase := range testCases {
+		if testCase.ExampleNum == exampleNum {
+			return testCase, nil
+		}
+	}
+
+	return TestCase{}, errors.Wrapf(ErrExampleNotFound, "example %d in spec %s", exampleNum, specVersion)
+}
+
+// Sections returns the names of the sections of the spec version in spec order.
+// Each name appears once.
+func Sections(specVersion string) ([]string, error) {
+	testCases, err := loadTestCases(specVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := []string{}
+
+	for _, group := range groupBySection(testCases) {
+		sections = append(sections, group[0].Section)
+	}
+
+	return sections, nil
+}
@@ -0,0 +1,100 @@
+package mdspec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  Cases()
+// ----------------------------------------------------------------------------
+
+func TestCases(t *testing.T) {
+	t.Parallel()
+
+	expected, _ := prepareTestCasesMap(t, "spec_v0.31.2.json")
+
+	testCases, err := Cases("v0.31.2")
+	require.NoError(t, err)
+	require.Equal(t, expected, testCases)
+
+	latest, err := Cases("latest")
+	require.NoError(t, err)
+	require.Equal(t, expected, latest, "latest should be resolved")
+}
+
+func TestCases_defensive_copy(t *testing.T) {
+	t.Parallel()
+
+	testCases, err := Cases("v0.31.2")
+	require.NoError(t, err)
+
+	original := testCases[0]
+	testCases[0].HTML = "corrupted"
+
+	again, err := Cases("v0.31.2")
+	require.NoError(t, err)
+	assert.Equal(t, original, again[0], "modifying the returned slice should not affect the others")
+
+	testCase, err := Case("v0.31.2", 1)
+	require.NoError(t, err)
+	assert.Equal(t, original, testCase)
+
+	require.NoError(t, SpecCheck("v0.31.2", func(string) (string, error) {
+		return original.HTML, nil
+	}, WithExamples(1)))
+}
+
+func TestCases_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Cases("v0.1")
+	require.ErrorIs(t, err, ErrSpecNotFound)
+
+	_, err = Cases("unknown")
+	require.ErrorIs(t, err, ErrInvalidVersion)
+}
+
+// ----------------------------------------------------------------------------
+//  Case()
+// ----------------------------------------------------------------------------
+
+func TestCase_example(t *testing.T) {
+	t.Parallel()
+
+	testCase, err := Case("v0.31.2", 12)
+	require.NoError(t, err)
+
+	assert.Equal(t, getTestCase(t, "spec_v0.31.2.json", 12), testCase)
+	assert.Equal(t, "Backslash escapes", testCase.Section)
+}
+
+func TestCase_not_found(t *testing.T) {
+	t.Parallel()
+
+	_, err := Case("v0.31.2", 653)
+	require.ErrorIs(t, err, ErrExampleNotFound)
+	assert.Contains(t, err.Error(), "example 653 in spec v0.31.2")
+
+	_, err = Case("unknown", 1)
+	require.ErrorIs(t, err, ErrInvalidVersion)
+}
+
+// ----------------------------------------------------------------------------
+//  Sections()
+// ----------------------------------------------------------------------------
+
+func TestSections(t *testing.T) {
+	t.Parallel()
+
+	sections, err := Sections("v0.31.2")
+	require.NoError(t, err)
+
+	require.Len(t, sections, 26)
+	assert.Equal(t, []string{"Tabs", "Backslash escapes", "Entity and numeric character references"}, sections[:3])
+
+	_, err = Sections("unknown")
+	require.ErrorIs(t, err, ErrInvalidVersion)
+}
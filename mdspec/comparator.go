@@ -0,0 +1,197 @@
+package mdspec
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Comparator decides whether the HTML returned by the function is equivalent to
+// the expected HTML of a test case.
+//
+// Compare returns true if they are equivalent. On a mismatch, it may return a
+// detail explaining the difference, which is set to the Detail field of
+// *MismatchError and Result. The detail can be empty.
+type Comparator interface {
+	Compare(testCase TestCase, expected, actual string) (bool, string)
+}
+
+// ComparatorFunc is an adapter to use an ordinary function as a Comparator.
+//
+// Usage:
+//
+//	// Ignore the trailing newline
+//	cmp := mdspec.ComparatorFunc(func(_ mdspec.TestCase, expected, actual string) (bool, string) {
+//		return strings.TrimSuffix(expected, "\n") == strings.TrimSuffix(actual, "\n"), ""
+//	})
+type ComparatorFunc func(testCase TestCase, expected, actual string) (bool, string)
+
+// Compare calls f(testCase, expected, actual).
+func (f ComparatorFunc) Compare(testCase TestCase, expected, actual string) (bool, string) {
+	return f(testCase, expected, actual)
+}
+
+// WithComparator sets the comparator to decide whether the function returned the
+// expected HTML. The default is StrictComparator. A nil comparator is ignored.
+//
+// Usage:
+//
+//	err := mdspec.SpecCheck("latest", myFunc,
+//		mdspec.WithComparator(mdspec.IgnoreAttributes(mdspec.NormalizedComparator(), "class")),
+//	)
+func WithComparator(comparator Comparator) Option {
+	return func(conf *config) {
+		if comparator != nil {
+			conf.comparator = comparator
+		}
+	}
+}
+
+// StrictComparator returns the default comparator that requires the HTML to be
+// byte-for-byte identical.
+func StrictComparator() Comparator {
+	return ComparatorFunc(compareStrict)
+}
+
+// NormalizedComparator returns a comparator that compares the HTML after
+// normalizing both with NormalizeHTML. On a mismatch, the detail is the diff of
+// the normalized HTML.
+func NormalizedComparator() Comparator {
+	return ComparatorFunc(compareNormalized)
+}
+
+// IgnoreAttributes returns a comparator that removes the given attributes from
+// the start tags of both HTML and then compares them with next. If next is nil,
+// StrictComparator is used.
+//
+// An attribute is given as its name, e.g. "class", to ignore it on all tags, or
+// as "tag.name", e.g. "a.rel", to ignore it only on the tag. Names are case
+// insensitive.
+//
+// Usage:
+//
+//	// Ignore the classes added by syntax highlighters, the IDs of headings and
+//	// the rel attributes of links
+//	cmp := mdspec.IgnoreAttributes(mdspec.NormalizedComparator(),
+//		"class", "h1.id", "h2.id", "h3.id", "h4.id", "h5.id", "h6.id", "a.rel",
+//	)
+func IgnoreAttributes(next Comparator, attrs ...string) Comparator {
+	if next == nil {
+		next = StrictComparator()
+	}
+
+	ignored := make(map[string]bool, len(attrs))
+	for _, attr := range attrs {
+		ignored[strings.ToLower(attr)] = true
+	}
+
+	return ComparatorFunc(func(testCase TestCase, expected, actual string) (bool, string) {
+		isIgnored := func(tag, attr string) bool {
+			return ignored[attr] || ignored[tag+"."+attr]
+		}
+
+		return next.Compare(testCase,
+			removeAttributes(expected, isIgnored),
+			removeAttributes(actual, isIgnored),
+		)
+	})
+}
+
+// AnyOf returns a comparator that passes if any of the given comparators passes.
+// The comparators are tried in order. On a mismatch, the detail is the non-empty
+// details of all the comparators joined by newlines. Nil comparators are skipped
+// and if there are none, StrictComparator is used.
+func AnyOf(comparators ...Comparator) Comparator {
+	valid := make([]Comparator, 0, len(comparators))
+
+	for _, comparator := range comparators {
+		if comparator != nil {
+			valid = append(valid, comparator)
+		}
+	}
+
+	if len(valid) == 0 {
+		return StrictComparator()
+	}
+
+	return ComparatorFunc(func(testCase TestCase, expected, actual string) (bool, string) {
+		details := []string{}
+
+		for _, comparator := range valid {
+			ok, detail := comparator.Compare(testCase, expected, actual)
+			if ok {
+				return true, ""
+			}
+
+			if detail != "" {
+				details = append(details, detail)
+			}
+		}
+
+		return false, strings.Join(details, "\n")
+	})
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+var (
+	// startTagRe matches an HTML start tag as defined in the CommonMark spec.
+	// The first two groups are the tag name and the attributes, and the last one
+	// is the end of the tag.
+	startTagRe = regexp.MustCompile(
+		`<([a-zA-Z][a-zA-Z0-9-]*)((?:` + attrPattern + `)*)(\s*/?>)`,
+	)
+	// attrRe matches an attribute with the leading whitespace. The group is the
+	// attribute name.
+	attrRe = regexp.MustCompile(attrPattern)
+)
+
+// attrPattern is the regexp pattern of an attribute with the leading whitespace.
+const attrPattern = `\s+([a-zA-Z_:][a-zA-Z0-9_.:-]*)` +
+	`(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?`
+
+// compare compares the expected and actual HTML with the comparator of the
+// config.
+func (conf *config) compare(testCase TestCase, actual string) (bool, string) {
+	return conf.comparator.Compare(testCase, testCase.HTML, actual)
+}
+
+// compareNormalized is the implementation of NormalizedComparator.
+func compareNormalized(_ TestCase, expected, actual string) (bool, string) {
+	if expected == actual {
+		return true, ""
+	}
+
+	normExpected, normActual := NormalizeHTML(expected), NormalizeHTML(actual)
+	if normExpected == normActual {
+		return true, ""
+	}
+
+	return false, "normalized HTML differs:\n" + Diff(normExpected, normActual)
+}
+
+// compareStrict is the implementation of StrictComparator.
+func compareStrict(_ TestCase, expected, actual string) (bool, string) {
+	return expected == actual, ""
+}
+
+// removeAttributes removes the attributes for which isIgnored returns true from
+// the start tags in the HTML. The tag and attribute names are given in lower
+// case.
+func removeAttributes(html string, isIgnored func(tag, attr string) bool) string {
+	return startTagRe.ReplaceAllStringFunc(html, func(startTag string) string {
+		groups := startTagRe.FindStringSubmatch(startTag)
+		tag := strings.ToLower(groups[1])
+
+		attrs := attrRe.ReplaceAllStringFunc(groups[2], func(attr string) string {
+			if isIgnored(tag, strings.ToLower(attrRe.FindStringSubmatch(attr)[1])) {
+				return ""
+			}
+
+			return attr
+		})
+
+		return "<" + groups[1] + attrs + groups[len(groups)-1]
+	})
+}
@@ -0,0 +1,201 @@
+package mdspec
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  WithComparator()
+// ----------------------------------------------------------------------------
+
+func TestWithComparator(t *testing.T) {
+	t.Parallel()
+
+	golden := getGoldenParser(t, "v0.31.2")
+
+	// Function that decorates the expected HTML like syntax highlighters and
+	// link renderers do
+	reHeading := regexp.MustCompile(`<h([1-6])>`)
+	myFunc := func(markdown string) (string, error) {
+		html, err := golden(markdown)
+
+		html = strings.ReplaceAll(html, "<pre>", `<pre class="highlight">`)
+		html = strings.ReplaceAll(html, "<a href=", `<a rel="nofollow" href=`)
+		html = reHeading.ReplaceAllString(html, `<h$1 id="heading">`)
+
+		return html, err
+	}
+
+	cmp := IgnoreAttributes(nil, "class", "a.rel",
+		"h1.id", "h2.id", "h3.id", "h4.id", "h5.id", "h6.id")
+
+	require.Error(t, SpecCheck("v0.31.2", myFunc), "strict comparison should fail")
+	require.NoError(t, SpecCheck("v0.31.2", myFunc, WithComparator(cmp)))
+	require.NoError(t, SpecCheck("v0.31.2", myFunc, WithComparator(cmp), WithComparator(nil)),
+		"nil comparator should be ignored")
+
+	report, err := RunSpec("v0.31.2", myFunc, WithComparator(cmp))
+	require.NoError(t, err)
+	assert.Equal(t, report.Total, report.Passed)
+}
+
+func TestWithComparator_detail(t *testing.T) {
+	t.Parallel()
+
+	var gotCase TestCase
+
+	cmp := ComparatorFunc(func(testCase TestCase, expected, actual string) (bool, string) {
+		gotCase = testCase
+
+		return false, "compared " + actual + " with " + expected
+	})
+
+	err := SpecCheckWithConcurrency("v0.30", func(string) (string, error) {
+		return "<p>bad HTML</p>", nil
+	}, -1, WithExamples(1), WithComparator(cmp))
+
+	var mismatchErr *MismatchError
+
+	require.ErrorAs(t, err, &mismatchErr)
+	assert.Equal(t, 1, gotCase.ExampleNum, "the comparator should receive the test case")
+
+	expectDetail := "compared <p>bad HTML</p> with <pre><code>foo\tbaz\t\tbim\n</code></pre>\n"
+
+	assert.Equal(t, expectDetail, mismatchErr.Detail)
+	assert.Contains(t, mismatchErr.Error(), "actual HTML: \"<p>bad HTML</p>\"\n"+expectDetail+"\n--- expected\n")
+
+	report, err := RunSpec("v0.30", func(string) (string, error) {
+		return "<p>bad HTML</p>", nil
+	}, WithExamples(1), WithComparator(cmp))
+	require.NoError(t, err)
+	assert.Equal(t, expectDetail, report.Results[0].Detail)
+}
+
+// ----------------------------------------------------------------------------
+//  Built-in comparators
+// ----------------------------------------------------------------------------
+
+func TestStrictComparator(t *testing.T) {
+	t.Parallel()
+
+	ok, detail := StrictComparator().Compare(TestCase{}, "<p>a</p>\n", "<p>a</p>\n")
+	require.True(t, ok)
+	require.Empty(t, detail)
+
+	ok, detail = StrictComparator().Compare(TestCase{}, "<br />", "<br/>")
+	require.False(t, ok)
+	require.Empty(t, detail)
+}
+
+func TestNormalizedComparator(t *testing.T) {
+	t.Parallel()
+
+	cmp := NormalizedComparator()
+
+	ok, _ := cmp.Compare(TestCase{}, "<p>a<br />\nb</p>\n", "<p>a<br/>b</p>")
+	require.True(t, ok)
+
+	ok, detail := cmp.Compare(TestCase{}, "<p>a</p>\n", "<p>b</p>\n")
+	require.False(t, ok)
+	assert.Equal(t, "normalized HTML differs:\n--- expected\n+++ actual\n"+
+		"@@ -1 +1 @@\n-<p>a</p>\n\\ No newline at end of file\n+<p>b</p>\n\\ No newline at end of file",
+		detail)
+}
+
+func TestIgnoreAttributes(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name     string
+		attrs    []string
+		expected string
+		actual   string
+		want     bool
+	}{
+		{
+			name:     "attribute on all tags",
+			attrs:    []string{"class"},
+			expected: `<pre><code class="language-go">x</code></pre>`,
+			actual:   `<pre class='hl'><code CLASS=go data-x="1">x</code></pre>`,
+			want:     false,
+		},
+		{
+			name:     "attribute on all tags except others",
+			attrs:    []string{"class", "data-x"},
+			expected: `<pre><code class="language-go">x</code></pre>`,
+			actual:   `<pre class='hl'><code CLASS=go data-x="1">x</code></pre>`,
+			want:     true,
+		},
+		{
+			name:     "attribute on a specific tag",
+			attrs:    []string{"A.Rel"},
+			expected: `<p><a href="/u">x</a></p>`,
+			actual:   `<p><a rel="nofollow" href="/u">x</a></p>`,
+			want:     true,
+		},
+		{
+			name:     "attribute on another tag",
+			attrs:    []string{"a.rel"},
+			expected: `<p><link href="/u"></p>`,
+			actual:   `<p><link rel="x" href="/u"></p>`,
+			want:     false,
+		},
+		{
+			name:     "value with angle brackets and self-closing tag",
+			attrs:    []string{"title"},
+			expected: `<img src="a.png" alt="a" />`,
+			actual:   `<img src="a.png" title="<b>" alt="a" />`,
+			want:     true,
+		},
+		{
+			name:     "escaped tag is not a tag",
+			attrs:    []string{"class"},
+			expected: `<p>&lt;a&gt;</p>`,
+			actual:   `<p>&lt;a class="x"&gt;</p>`,
+			want:     false,
+		},
+	} {
+		ok, _ := IgnoreAttributes(nil, test.attrs...).Compare(TestCase{}, test.expected, test.actual)
+		assert.Equal(t, test.want, ok, test.name)
+	}
+
+	// Composed with the normalized comparator
+	cmp := IgnoreAttributes(NormalizedComparator(), "id")
+
+	ok, _ := cmp.Compare(TestCase{}, "<h1>x</h1>\n", `<h1 id="x">x</h1>`)
+	require.True(t, ok)
+}
+
+func TestAnyOf(t *testing.T) {
+	t.Parallel()
+
+	failing := func(detail string) Comparator {
+		return ComparatorFunc(func(TestCase, string, string) (bool, string) {
+			return false, detail
+		})
+	}
+
+	cmp := AnyOf(StrictComparator(), nil, IgnoreAttributes(nil, "class"))
+
+	ok, _ := cmp.Compare(TestCase{}, "<p>x</p>", "<p>x</p>")
+	require.True(t, ok)
+
+	ok, _ = cmp.Compare(TestCase{}, "<p>x</p>", `<p class="y">x</p>`)
+	require.True(t, ok)
+
+	ok, detail := AnyOf(failing("foo"), failing(""), failing("bar")).Compare(TestCase{}, "a", "b")
+	require.False(t, ok)
+	require.Equal(t, "foo\nbar", detail)
+
+	// No comparators falls back to the strict comparison
+	ok, _ = AnyOf(nil).Compare(TestCase{}, "a", "a")
+	require.True(t, ok)
+
+	ok, _ = AnyOf().Compare(TestCase{}, "a", "b")
+	require.False(t, ok)
+}
@@ -0,0 +1,155 @@
+package mdspec
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ParseFunc is the type constraint of the function to check. The function may
+// take a context, which is canceled when the run is canceled or the example
+// times out. See WithTimeout.
+type ParseFunc interface {
+	func(markdown string) (string, error) |
+		func(ctx context.Context, markdown string) (string, error)
+}
+
+// SpecCheckContext is the same as SpecCheck but stops on the cancellation of
+// "ctx". No more test cases are started after the cancellation and the returned
+// error wraps ctx.Err().
+//
+// The function may also be a context-aware one, which receives a context that
+// is done on the cancellation or on the timeout of the example.
+//
+// Usage:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//	defer cancel()
+//
+//	err := mdspec.SpecCheckContext(ctx, "latest", myFunc, mdspec.WithTimeout(time.Second))
+func SpecCheckContext[F ParseFunc](ctx context.Context, specVersion string, yourFunc F, opts ...Option) error {
+	return specCheck(ctx, specVersion, toParseFunc(yourFunc), newConfig(opts))
+}
+
+// RunSpecContext is the same as RunSpec but stops on the cancellation of "ctx".
+// No more test cases are started after the cancellation and it returns an error
+// that wraps ctx.Err() instead of a report.
+//
+// The function may also be a context-aware one. See SpecCheckContext.
+func RunSpecContext[F ParseFunc](
+	ctx context.Context, specVersion string, yourFunc F, opts ...Option,
+) (*Report, error) {
+	return runSpec(ctx, specVersion, toParseFunc(yourFunc), newConfig(opts))
+}
+
+// WithTimeout sets the maximum duration for the function to convert the markdown
+// of each example. If the function does not return in time, the example fails
+// with a *TimeoutError, or StatusTimeout in the report, and the run goes on to
+// the next example. A duration of zero or less means no timeout, which is the
+// default.
+//
+// Since a goroutine cannot be stopped from outside, the function call that timed
+// out is left running in the background. Context-aware functions should return
+// when their context is done.
+func WithTimeout(timeout time.Duration) Option {
+	return func(conf *config) {
+		conf.timeout = timeout
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// parseFunc is the common form of the functions to check.
+type parseFunc func(ctx context.Context, markdown string) (string, error)
+
+// callOutput is the outcome of a function call.
+type callOutput struct {
+	// err is the error returned by the function, or the error of the context
+	// if it is done before the function returns.
+	err error
+	// panicErr is set if the function panicked. Its TestCase is not set.
+	panicErr *PanicError
+	html     string
+	// timedOut is true if the function did not return in time.
+	timedOut bool
+}
+
+// callFunc calls the function with the markdown and recovers a panic in it. If
+// the context is done or the timeout is exceeded before the function returns,
+// it returns without waiting for the function.
+func callFunc(ctx context.Context, yourFunc parseFunc, markdown string, timeout time.Duration) callOutput {
+	if err := ctx.Err(); err != nil {
+		return callOutput{err: err}
+	}
+
+	if timeout <= 0 && ctx.Done() == nil {
+		return invokeFunc(ctx, yourFunc, markdown)
+	}
+
+	callCtx := ctx
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Buffered so that the goroutine can finish even if nobody receives.
+	chOutput := make(chan callOutput, 1)
+
+	go func() {
+		chOutput <- invokeFunc(callCtx, yourFunc, markdown)
+	}()
+
+	select {
+	case out := <-chOutput:
+		// A context-aware function may return on the timeout before us.
+		if timeout > 0 && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return callOutput{html: out.html, timedOut: true}
+		}
+
+		return out
+	case <-callCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return callOutput{err: err}
+		}
+
+		return callOutput{timedOut: true}
+	}
+}
+
+// errCanceled returns the error to return when the run is canceled.
+func errCanceled(ctx context.Context) error {
+	return errors.Wrap(ctx.Err(), "spec run canceled")
+}
+
+// invokeFunc calls the function and turns a panic in it into a *PanicError.
+func invokeFunc(ctx context.Context, yourFunc parseFunc, markdown string) (out callOutput) {
+	defer func() {
+		if value := recover(); value != nil {
+			out = callOutput{panicErr: &PanicError{Value: value, Stack: string(debug.Stack())}}
+		}
+	}()
+
+	out.html, out.err = yourFunc(ctx, markdown)
+
+	return out
+}
+
+// toParseFunc converts the function to check into the common form.
+func toParseFunc[F ParseFunc](yourFunc F) parseFunc {
+	if ctxFunc, ok := any(yourFunc).(func(context.Context, string) (string, error)); ok {
+		return ctxFunc
+	}
+
+	plainFunc, _ := any(yourFunc).(func(string) (string, error))
+
+	return func(_ context.Context, markdown string) (string, error) {
+		return plainFunc(markdown)
+	}
+}
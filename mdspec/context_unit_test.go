@@ -0,0 +1,247 @@
+package mdspec
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  SpecCheckContext() and RunSpecContext()
+// ----------------------------------------------------------------------------
+
+func TestSpecCheckContext(t *testing.T) {
+	t.Parallel()
+
+	golden := getGoldenParser(t, "v0.30")
+
+	// Plain function
+	require.NoError(t, SpecCheckContext(context.Background(), "v0.30", golden))
+
+	// Context-aware function
+	ctxFunc := func(ctx context.Context, markdown string) (string, error) {
+		assert.NoError(t, ctx.Err())
+
+		return golden(markdown)
+	}
+
+	require.NoError(t, SpecCheckContext(context.Background(), "v0.30", ctxFunc))
+	require.NoError(t, SpecCheckContext(context.Background(), "v0.30", ctxFunc, WithConcurrency(-1)))
+
+	report, err := RunSpecContext(context.Background(), "v0.30", ctxFunc)
+	require.NoError(t, err)
+	assert.Equal(t, report.Total, report.Passed)
+}
+
+func TestSpecCheckContext_direct_call(t *testing.T) {
+	t.Parallel()
+
+	golden := getGoldenParser(t, "v0.30")
+	directCalls := atomic.Int64{}
+
+	// The function is called directly without a goroutine only if the context
+	// given to it can never be done.
+	ctxFunc := func(ctx context.Context, markdown string) (string, error) {
+		if ctx.Done() == nil {
+			directCalls.Add(1)
+		}
+
+		return golden(markdown)
+	}
+
+	report, err := RunSpecContext(context.Background(), "v0.30", ctxFunc, WithExampleRange(1, 20))
+	require.NoError(t, err)
+	require.NoError(t, SpecCheckContext(context.Background(), "v0.30", ctxFunc, WithExampleRange(1, 20)))
+
+	assert.Equal(t, int64(2*report.Total), directCalls.Load(),
+		"concurrent runs without a timeout should not wrap the background context")
+}
+
+func TestSpecCheckContext_canceled(t *testing.T) {
+	t.Parallel()
+
+	for _, maxConcurrency := range []int{-1, 0, 2} {
+		// Canceled before the run
+		var numCalls atomic.Int32
+
+		canceledCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		countCalls := func(string) (string, error) {
+			numCalls.Add(1)
+
+			return "", nil
+		}
+
+		err := SpecCheckContext(canceledCtx, "v0.30", countCalls, WithConcurrency(maxConcurrency))
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorContains(t, err, "spec run canceled")
+
+		report, err := RunSpecContext(canceledCtx, "v0.30", countCalls, WithConcurrency(maxConcurrency))
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, report)
+		require.Zero(t, numCalls.Load(), "no test should start after cancellation")
+
+		// Canceled during the run by the function itself
+		ctx, cancel := context.WithCancel(context.Background())
+
+		err = SpecCheckContext(ctx, "v0.30", func(string) (string, error) {
+			if numCalls.Add(1) == 10 {
+				cancel()
+			}
+
+			return "", nil
+		}, WithConcurrency(maxConcurrency), WithKnownFailures(makeRange(1, 652)...))
+
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, numCalls.Load(), int32(652), "tests should stop after cancellation")
+	}
+}
+
+func TestSpecCheckContext_canceled_hanging_func(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	// Function that hangs until the end of the test
+	hangingFunc := func(string) (string, error) {
+		<-release
+
+		return "", nil
+	}
+
+	for _, maxConcurrency := range []int{-1, 2} {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+
+		err := SpecCheckContext(ctx, "v0.30", hangingFunc, WithConcurrency(maxConcurrency))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		_, err = RunSpecContext(ctx, "v0.30", hangingFunc, WithConcurrency(maxConcurrency))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		cancel()
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  WithTimeout()
+// ----------------------------------------------------------------------------
+
+func TestWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	golden := getGoldenParser(t, "v0.30")
+	hangingMarkdown := getTestCase(t, "spec_v0.30.json", 5).Markdown
+
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	// Plain function that hangs on example 5
+	plainFunc := func(markdown string) (string, error) {
+		if markdown == hangingMarkdown {
+			<-release
+		}
+
+		return golden(markdown)
+	}
+
+	// Context-aware function that returns the error of the context on example 5
+	ctxFunc := func(ctx context.Context, markdown string) (string, error) {
+		if markdown == hangingMarkdown {
+			<-ctx.Done()
+
+			return "", ctx.Err()
+		}
+
+		return golden(markdown)
+	}
+
+	for _, maxConcurrency := range []int{-1, 0} {
+		opts := []Option{
+			WithExampleRange(1, 10),
+			WithTimeout(20 * time.Millisecond),
+			WithConcurrency(maxConcurrency),
+		}
+
+		for name, err := range map[string]error{
+			"plain":         SpecCheck("v0.30", plainFunc, opts...),
+			"context-aware": SpecCheckContext(context.Background(), "v0.30", ctxFunc, opts...),
+		} {
+			var timeoutErr *TimeoutError
+
+			require.ErrorAs(t, err, &timeoutErr, name)
+			assert.Equal(t, 5, timeoutErr.TestCase.ExampleNum, name)
+			assert.Equal(t, 20*time.Millisecond, timeoutErr.Timeout, name)
+			assert.Equal(t,
+				"error 5_Tabs: the given function timed out after 20ms.\n"+
+					"given markdown: \"- foo\\n\\n\\t\\tbar\\n\"",
+				timeoutErr.Error(), name)
+		}
+
+		report, err := RunSpec("v0.30", plainFunc, opts...)
+		require.NoError(t, err)
+
+		assert.Equal(t, 9, report.Passed)
+		assert.Equal(t, 1, report.TimedOut)
+		assert.Equal(t, 1, report.Sections()[0].TimedOut)
+		assert.Equal(t, StatusTimeout, report.Results[4].Status)
+		assert.Equal(t, "timeout", report.Results[4].Status.String())
+		require.ErrorAs(t, report.Results[4].Err, new(*TimeoutError))
+
+		// Known failures may time out
+		require.NoError(t, SpecCheck("v0.30", plainFunc, append(opts, WithKnownFailures(5))...))
+	}
+}
+
+func TestWithTimeout_in_time(t *testing.T) {
+	t.Parallel()
+
+	golden := getGoldenParser(t, "v0.30")
+
+	ctxFunc := func(ctx context.Context, markdown string) (string, error) {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline, "the context should have the deadline of the timeout")
+
+		return golden(markdown)
+	}
+
+	require.NoError(t, SpecCheckContext(context.Background(), "v0.30", ctxFunc, WithTimeout(time.Minute)))
+	require.NoError(t, SpecCheck("v0.30", golden, WithTimeout(time.Minute), WithTimeout(0)))
+}
+
+// ----------------------------------------------------------------------------
+//  Helper functions
+// ----------------------------------------------------------------------------
+
+// getTestCase returns the test case of the example number in the spec file.
+func getTestCase(t *testing.T, nameFile string, exampleNum int) TestCase {
+	t.Helper()
+
+	testCases, _ := prepareTestCasesMap(t, nameFile)
+
+	for _, testCase := range testCases {
+		if testCase.ExampleNum == exampleNum {
+			return testCase
+		}
+	}
+
+	t.Fatalf("example %d not found in %s", exampleNum, nameFile)
+
+	return TestCase{}
+}
+
+// makeRange returns the numbers from first to last.
+func makeRange(first, last int) []int {
+	nums := make([]int, 0, last-first+1)
+
+	for num := first; num <= last; num++ {
+		nums = append(nums, num)
+	}
+
+	return nums
+}
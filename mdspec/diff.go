@@ -0,0 +1,350 @@
+package mdspec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markers to make invisible characters visible in diffs. The tab marker is the
+// same as the one used in the CommonMark spec.
+const (
+	markerTab       = "→"
+	markerCR        = "␍"
+	markerSpace     = "·"
+	markerNewline   = "⏎"
+	markerNoNewline = `\ No newline at end of file`
+)
+
+// diffContextLines is the number of unchanged lines shown around the changes
+// in a unified diff.
+const diffContextLines = 3
+
+// Diff returns a line-oriented unified diff between the expected and actual
+// HTML. It returns an empty string if they are equal.
+//
+// To make whitespace differences visible, tabs are shown as "→", carriage
+// returns as "␍" and trailing spaces as "·". A missing final newline is marked
+// with "\ No newline at end of file", as in "diff -u".
+//
+// Example output:
+//
+//	--- expected
+//	+++ actual
+//	@@ -1,2 +1,2 @@
+//	-<pre><code>foo→baz
+//	+<pre><code>foo    baz··
+//	 </code></pre>
+func Diff(expected, actual string) string {
+	return diffLabeled("expected", "actual", expected, actual)
+}
+
+// InlineDiff returns a character-level diff between the expected and actual
+// HTML. Removed text is enclosed in "[-" and "-]" and added text in "{+" and
+// "+}". Tabs, carriage returns and trailing spaces are made visible as in Diff,
+// and newlines are shown as "⏎" followed by a line break. In the removed and
+// added text, all whitespace is made visible and newlines are shown as "⏎"
+// without a line break. It returns an empty string if they are equal.
+//
+// Example output:
+//
+//	<pre><code>foo[-→-]{+····+}baz⏎
+//	</code></pre>⏎
+func InlineDiff(expected, actual string) string {
+	if expected == actual {
+		return ""
+	}
+
+	// Trim the common prefix and suffix to keep the diff computation small.
+	runesExpect, runesActual := []rune(expected), []rune(actual)
+	prefix := commonPrefixLen(runesExpect, runesActual)
+	suffix := commonSuffixLen(runesExpect[prefix:], runesActual[prefix:])
+
+	edits := diffEdits(
+		runesExpect[prefix:len(runesExpect)-suffix],
+		runesActual[prefix:len(runesActual)-suffix],
+	)
+
+	var out strings.Builder
+
+	out.WriteString(visibleInline(string(runesExpect[:prefix])))
+
+	for index := 0; index < len(edits); {
+		kind := edits[index].kind
+
+		var chunk []rune
+
+		for ; index < len(edits) && edits[index].kind == kind; index++ {
+			chunk = append(chunk, edits[index].value)
+		}
+
+		switch kind {
+		case editEqual:
+			out.WriteString(visibleInline(string(chunk)))
+		case editDelete:
+			out.WriteString("[-" + visibleChange(string(chunk)) + "-]")
+		case editInsert:
+			out.WriteString("{+" + visibleChange(string(chunk)) + "+}")
+		}
+	}
+
+	out.WriteString(visibleInline(string(runesExpect[len(runesExpect)-suffix:])))
+
+	return out.String()
+}
+
+// Diff returns the unified diff between the expected and actual HTML. See the
+// package-level Diff function for the format.
+func (e *MismatchError) Diff() string {
+	return Diff(e.Expected, e.Actual)
+}
+
+// InlineDiff returns the character-level diff between the expected and actual
+// HTML. See the package-level InlineDiff function for the format.
+func (e *MismatchError) InlineDiff() string {
+	return InlineDiff(e.Expected, e.Actual)
+}
+
+// Diff returns the unified diff between the expected HTML of the test case and
+// the actual HTML. It returns an empty string if the test case passed.
+func (r Result) Diff() string {
+	return Diff(r.TestCase.HTML, r.Actual)
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// editKind is the kind of an edit operation.
+type editKind int
+
+const (
+	editEqual editKind = iota
+	editDelete
+	editInsert
+)
+
+// editPrefixes are the line prefixes of each edit kind in a unified diff.
+var editPrefixes = [...]string{editEqual: " ", editDelete: "-", editInsert: "+"}
+
+// edit is an edit operation to transform a sequence into another.
+type edit[T comparable] struct {
+	value T
+	kind  editKind
+	// indexA and indexB are the positions of the element in each sequence.
+	// The one that does not apply to the kind is the position of the next
+	// element.
+	indexA int
+	indexB int
+}
+
+// hunk is a group of edits with surrounding context.
+type hunk struct {
+	edits []edit[string]
+}
+
+// commonPrefixLen returns the length of the common prefix of a and b.
+func commonPrefixLen[T comparable](a, b []T) int {
+	length := 0
+
+	for length < len(a) && length < len(b) && a[length] == b[length] {
+		length++
+	}
+
+	return length
+}
+
+// commonSuffixLen returns the length of the common suffix of a and b.
+func commonSuffixLen[T comparable](a, b []T) int {
+	length := 0
+
+	for length < len(a) && length < len(b) && a[len(a)-1-length] == b[len(b)-1-length] {
+		length++
+	}
+
+	return length
+}
+
+// diffLabeled returns the unified diff of Diff with the given labels of the
+// old and new texts in the header.
+func diffLabeled(labelOld, labelNew, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	edits := diffEdits(splitLines(oldText), splitLines(newText))
+
+	var out strings.Builder
+
+	out.WriteString("--- " + labelOld + "\n+++ " + labelNew + "\n")
+
+	for _, hunk := range groupHunks(edits, diffContextLines) {
+		writeHunk(&out, hunk)
+	}
+
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// diffEdits returns the shortest edit script to transform a into b using the
+// longest common subsequence. Deletions are placed before insertions.
+func diffEdits[T comparable](a, b []T) []edit[T] {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]edit[T], 0, len(a)+len(b))
+	i, j := 0, 0
+
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit[T]{value: a[i], kind: editEqual, indexA: i, indexB: j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit[T]{value: a[i], kind: editDelete, indexA: i, indexB: j})
+			i++
+		default:
+			edits = append(edits, edit[T]{value: b[j], kind: editInsert, indexA: i, indexB: j})
+			j++
+		}
+	}
+
+	return edits
+}
+
+// groupHunks groups the edits into hunks with the given number of context lines
+// around the changes.
+func groupHunks(edits []edit[string], numContext int) []hunk {
+	hunks := []hunk{}
+	start, end := -1, -1
+
+	for index, e := range edits {
+		if e.kind == editEqual {
+			continue
+		}
+
+		from := max(index-numContext, 0)
+		if start >= 0 && from > end {
+			hunks = append(hunks, hunk{edits: edits[start:end]})
+			start = -1
+		}
+
+		if start < 0 {
+			start = from
+		}
+
+		end = min(index+numContext+1, len(edits))
+	}
+
+	if start >= 0 {
+		hunks = append(hunks, hunk{edits: edits[start:end]})
+	}
+
+	return hunks
+}
+
+// hunkRange returns the range of a hunk header in the unified diff format.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits the text into lines keeping the trailing newlines.
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// visibleChange makes all the whitespace in a changed text visible.
+func visibleChange(text string) string {
+	return strings.NewReplacer(
+		" ", markerSpace,
+		"\t", markerTab,
+		"\r", markerCR,
+		"\n", markerNewline,
+	).Replace(text)
+}
+
+// visibleInline makes tabs, carriage returns, newlines and the spaces before a
+// newline visible.
+func visibleInline(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+
+	for index, line := range lines {
+		body, hasNewline := strings.CutSuffix(line, "\n")
+		if hasNewline {
+			lines[index] = visibleLine(body) + markerNewline + "\n"
+		} else {
+			lines[index] = visibleLine(body)
+		}
+	}
+
+	return strings.Join(lines, "")
+}
+
+// visibleLine makes tabs, carriage returns and trailing spaces of a line without
+// the newline visible.
+func visibleLine(line string) string {
+	body := strings.TrimRight(line, " \r")
+	trailing := visibleChange(line[len(body):])
+
+	body = strings.ReplaceAll(body, "\t", markerTab)
+	body = strings.ReplaceAll(body, "\r", markerCR)
+
+	return body + trailing
+}
+
+// writeHunk writes a hunk in the unified diff format.
+func writeHunk(out *strings.Builder, hunk hunk) {
+	first := hunk.edits[0]
+	countA, countB := 0, 0
+
+	for _, e := range hunk.edits {
+		if e.kind != editInsert {
+			countA++
+		}
+
+		if e.kind != editDelete {
+			countB++
+		}
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(first.indexA, countA), hunkRange(first.indexB, countB))
+
+	for _, e := range hunk.edits {
+		body, hasNewline := strings.CutSuffix(e.value, "\n")
+
+		out.WriteString(editPrefixes[e.kind] + visibleLine(body) + "\n")
+
+		if !hasNewline {
+			out.WriteString(markerNoNewline + "\n")
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package mdspec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  Diff()
+// ----------------------------------------------------------------------------
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name     string
+		expected string
+		actual   string
+		want     string
+	}{
+		{
+			name:     "equal",
+			expected: "<p>foo</p>\n",
+			actual:   "<p>foo</p>\n",
+			want:     "",
+		},
+		{
+			name:     "changed line with tab",
+			expected: "<pre><code>foo\tbaz\n</code></pre>\n",
+			actual:   "<pre><code>foo    baz\n</code></pre>\n",
+			want: "--- expected\n+++ actual\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				"-<pre><code>foo→baz\n" +
+				"+<pre><code>foo    baz\n" +
+				" </code></pre>",
+		},
+		{
+			name:     "trailing spaces and carriage return",
+			expected: "<p>foo</p>\n",
+			actual:   "<p>foo</p>  \r\n",
+			want: "--- expected\n+++ actual\n" +
+				"@@ -1 +1 @@\n" +
+				"-<p>foo</p>\n" +
+				"+<p>foo</p>··␍",
+		},
+		{
+			name:     "missing final newline",
+			expected: "<p>foo</p>\n",
+			actual:   "<p>foo</p>",
+			want: "--- expected\n+++ actual\n" +
+				"@@ -1 +1 @@\n" +
+				"-<p>foo</p>\n" +
+				"+<p>foo</p>\n" +
+				`\ No newline at end of file`,
+		},
+		{
+			name:     "empty expected",
+			expected: "",
+			actual:   "<hr />\n",
+			want: "--- expected\n+++ actual\n" +
+				"@@ -0,0 +1 @@\n" +
+				"+<hr />",
+		},
+		{
+			name:     "separate hunks",
+			expected: "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n",
+			actual:   "a\nB\nc\nd\ne\nf\ng\nh\ni\nJ\nk\nl\n",
+			want: "--- expected\n+++ actual\n" +
+				"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+				"@@ -7,5 +7,6 @@\n g\n h\n i\n-j\n+J\n k\n+l",
+		},
+	} {
+		require.Equal(t, test.want, Diff(test.expected, test.actual), test.name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  InlineDiff()
+// ----------------------------------------------------------------------------
+
+func TestInlineDiff(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name     string
+		expected string
+		actual   string
+		want     string
+	}{
+		{
+			name:     "equal",
+			expected: "<p>foo</p>\n",
+			actual:   "<p>foo</p>\n",
+			want:     "",
+		},
+		{
+			name:     "tab versus spaces",
+			expected: "<pre><code>foo\tbaz\n</code></pre>\n",
+			actual:   "<pre><code>foo    baz\n</code></pre>\n",
+			want:     "<pre><code>foo[-→-]{+····+}baz⏎\n</code></pre>⏎\n",
+		},
+		{
+			name:     "missing final newline",
+			expected: "<p>foo</p>\n",
+			actual:   "<p>foo</p>",
+			want:     "<p>foo</p>[-⏎-]",
+		},
+		{
+			name:     "self-closing tag",
+			expected: "<p>a<br />\nb  </p>\n",
+			actual:   "<p>a<br/>\nb  </p>\n",
+			want:     "<p>a<br[-·-]/>⏎\nb  </p>⏎\n",
+		},
+		{
+			name:     "multibyte",
+			expected: "<p>ὐ</p>",
+			actual:   "<p>ὑ</p>",
+			want:     "<p>[-ὐ-]{+ὑ+}</p>",
+		},
+	} {
+		require.Equal(t, test.want, InlineDiff(test.expected, test.actual), test.name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Diff() methods
+// ----------------------------------------------------------------------------
+
+func TestMismatchError_Diff(t *testing.T) {
+	t.Parallel()
+
+	err := SpecCheck("v0.30", func(string) (string, error) {
+		return "<pre><code>foo    baz        bim\n</code></pre>\n", nil
+	}, WithExamples(1))
+
+	var mismatchErr *MismatchError
+
+	require.ErrorAs(t, err, &mismatchErr)
+
+	expectDiff := "--- expected\n+++ actual\n" +
+		"@@ -1,2 +1,2 @@\n" +
+		"-<pre><code>foo→baz→→bim\n" +
+		"+<pre><code>foo    baz        bim\n" +
+		" </code></pre>"
+
+	assert.Equal(t, expectDiff, mismatchErr.Diff())
+	assert.Equal(t,
+		"<pre><code>foo[-→-]{+····+}baz[-→→-]{+········+}bim⏎\n</code></pre>⏎\n",
+		mismatchErr.InlineDiff(),
+	)
+	assert.True(t, strings.HasSuffix(mismatchErr.Error(), "\n"+expectDiff),
+		"error message should end with the diff")
+}
+
+func TestResult_Diff(t *testing.T) {
+	t.Parallel()
+
+	report, err := RunSpec("v0.30", func(markdown string) (string, error) {
+		return "<p>" + strings.TrimSpace(markdown) + "</p>\n", nil
+	}, WithExamples(1, 650))
+	require.NoError(t, err)
+
+	require.Equal(t, StatusFail, report.Results[0].Status)
+	require.Equal(t, StatusPass, report.Results[1].Status)
+
+	assert.Contains(t, report.Results[0].Diff(), "+<p>foo→baz→→bim</p>")
+	assert.Empty(t, report.Results[1].Diff(), "passed result should have no diff")
+}
@@ -0,0 +1,178 @@
+package mdspec
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sentinel errors that can be checked with errors.Is.
+var (
+	// ErrInvalidVersion is returned if the spec version is not in a valid format.
+	ErrInvalidVersion = errors.New("invalid spec version format")
+	// ErrSpecNotFound is returned if there is no embedded spec of the version.
+	ErrSpecNotFound = errors.New("spec file not found")
+	// ErrNoTestCases is returned if no test case matches the given filters.
+	ErrNoTestCases = errors.New("no test cases match the given filters")
+	// ErrExampleNotFound is returned if there is no example of the number in
+	// the spec.
+	ErrExampleNotFound = errors.New("example not found")
+	// ErrInvalidSuite is returned if the test cases of a suite are malformed.
+	ErrInvalidSuite = errors.New("invalid suite")
+	// ErrBaselineVersion is returned if the baseline is for another spec
+	// version than the one to run.
+	ErrBaselineVersion = errors.New("baseline is for another spec version")
+)
+
+// MismatchError is the error of a test case in which the function returned an
+// HTML different from the expected one.
+type MismatchError struct {
+	// Expected is the expected HTML.
+	Expected string
+	// Actual is the HTML returned by the function.
+	Actual string
+	// Detail is the explanation of the comparator. It may be empty. See
+	// Comparator.
+	Detail string
+	// TestCase is the failed test case.
+	TestCase TestCase
+}
+
+// Error implements the error interface. The message ends with the detail of the
+// comparator, if any, and the unified diff of the expected and actual HTML. See
+// Diff for the format.
+func (e *MismatchError) Error() string {
+	msg := fmt.Sprintf(
+		"error %s: the given function did not return the expected HTML result.\n"+
+			"given markdown: %#v\nexpect HTML: %#v\nactual HTML: %#v\n",
+		nameTest(e.TestCase), e.TestCase.Markdown, e.Expected, e.Actual,
+	)
+
+	if e.Detail != "" {
+		msg += e.Detail + "\n"
+	}
+
+	return msg + e.Diff()
+}
+
+// FuncError is the error of a test case in which the function returned an error.
+type FuncError struct {
+	// Err is the error returned by the function.
+	Err error
+	// Actual is the HTML returned by the function along with the error.
+	Actual string
+	// TestCase is the failed test case.
+	TestCase TestCase
+}
+
+// Error implements the error interface.
+func (e *FuncError) Error() string {
+	return fmt.Sprintf(
+		"error %s: the given function failed to parse markdown.\n"+
+			"given markdown: %#v\nexpect HTML: %#v\nactual HTML: %#v: %v",
+		nameTest(e.TestCase), e.TestCase.Markdown, e.TestCase.HTML, e.Actual, e.Err,
+	)
+}
+
+// Unwrap returns the error returned by the function.
+func (e *FuncError) Unwrap() error {
+	return e.Err
+}
+
+// TimeoutError is the error of a test case in which the function did not return
+// within the duration set by WithTimeout.
+type TimeoutError struct {
+	// Timeout is the duration the function exceeded.
+	Timeout time.Duration
+	// TestCase is the timed out test case.
+	TestCase TestCase
+}
+
+// Error implements the error interface.
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf(
+		"error %s: the given function timed out after %s.\ngiven markdown: %#v",
+		nameTest(e.TestCase), e.Timeout, e.TestCase.Markdown,
+	)
+}
+
+// PanicError is the error of a test case in which the function panicked. The
+// panic is recovered so that the other test cases keep running.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack string
+	// TestCase is the test case that caused the panic.
+	TestCase TestCase
+}
+
+// Error implements the error interface. The message ends with the stack trace.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf(
+		"error %s: the given function panicked: %v\ngiven markdown: %#v\nstack trace:\n%s",
+		nameTest(e.TestCase), e.Value, e.TestCase.Markdown, e.Stack,
+	)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+
+	return err
+}
+
+// UnexpectedPassError is the error of a test case that is listed as a known
+// failure but passed.
+type UnexpectedPassError struct {
+	// TestCase is the test case that passed.
+	TestCase TestCase
+}
+
+// Error implements the error interface.
+func (e *UnexpectedPassError) Error() string {
+	return fmt.Sprintf(
+		"error %s: the example is listed as a known failure but passed. "+
+			"remove it from the list of known failures",
+		nameTest(e.TestCase),
+	)
+}
+
+// ThresholdError is the error of a run in which the pass rate of all the test
+// cases or of a section is below its threshold. See WithThresholds.
+type ThresholdError struct {
+	// Misses holds the thresholds that were not met in the given order.
+	Misses []ThresholdMiss
+}
+
+// Error implements the error interface. Each missed threshold is on its own
+// line.
+func (e *ThresholdError) Error() string {
+	lines := make([]string, 0, len(e.Misses)+1)
+	lines = append(lines, fmt.Sprintf("%d pass rate threshold(s) not met:", len(e.Misses)))
+
+	for _, miss := range e.Misses {
+		lines = append(lines, "  "+miss.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// joinErrors returns an error that wraps all the non-nil errors. It returns nil
+// if there are none. The errors can be inspected with errors.Is and errors.As.
+func joinErrors(errs []error) error {
+	return stderrors.Join(errs...)
+}
+
+// nameTest returns the name of the test case used in error messages. E.g.
+// "1_Tabs".
+func nameTest(testCase TestCase) string {
+	return fmt.Sprintf("%d_%s", testCase.ExampleNum, testCase.Section)
+}
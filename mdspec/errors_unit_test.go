@@ -0,0 +1,226 @@
+package mdspec
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  Typed errors
+// ----------------------------------------------------------------------------
+
+func TestSpecCheck_mismatch_error(t *testing.T) {
+	t.Parallel()
+
+	err := SpecCheck("v0.30", func(string) (string, error) {
+		return "<p>bad HTML</p>", nil
+	}, WithExamples(1))
+	require.Error(t, err)
+
+	var mismatchErr *MismatchError
+
+	require.ErrorAs(t, err, &mismatchErr)
+	assert.Equal(t, 1, mismatchErr.TestCase.ExampleNum)
+	assert.Equal(t, "Tabs", mismatchErr.TestCase.Section)
+	assert.Equal(t, mismatchErr.TestCase.HTML, mismatchErr.Expected)
+	assert.Equal(t, "<p>bad HTML</p>", mismatchErr.Actual)
+	assert.Equal(t,
+		"error 1_Tabs: the given function did not return the expected HTML result.\n"+
+			"given markdown: \"\\tfoo\\tbaz\\t\\tbim\\n\"\n"+
+			"expect HTML: \"<pre><code>foo\\tbaz\\t\\tbim\\n</code></pre>\\n\"\n"+
+			"actual HTML: \"<p>bad HTML</p>\"\n"+
+			"--- expected\n"+
+			"+++ actual\n"+
+			"@@ -1,2 +1 @@\n"+
+			"-<pre><code>foo→baz→→bim\n"+
+			"-</code></pre>\n"+
+			"+<p>bad HTML</p>\n"+
+			"\\ No newline at end of file",
+		mismatchErr.Error(),
+	)
+}
+
+func TestSpecCheck_func_error(t *testing.T) {
+	t.Parallel()
+
+	errForced := errors.New("forced error")
+
+	err := SpecCheck("v0.30", func(string) (string, error) {
+		return "partial", errForced
+	}, WithExamples(1))
+	require.Error(t, err)
+	require.ErrorIs(t, err, errForced, "the error of the function should be unwrapped")
+
+	var funcErr *FuncError
+
+	require.ErrorAs(t, err, &funcErr)
+	assert.Equal(t, 1, funcErr.TestCase.ExampleNum)
+	assert.Equal(t, "partial", funcErr.Actual)
+	assert.Contains(t, funcErr.Error(), "error 1_Tabs: the given function failed to parse markdown")
+	assert.True(t, strings.HasSuffix(funcErr.Error(), `actual HTML: "partial": forced error`))
+}
+
+func TestSpecCheck_unexpected_pass_error(t *testing.T) {
+	t.Parallel()
+
+	err := SpecCheck("v0.30", getGoldenParser(t, "v0.30"), WithExamples(1, 2), WithKnownFailures(2))
+	require.Error(t, err)
+
+	var passErr *UnexpectedPassError
+
+	require.ErrorAs(t, err, &passErr)
+	assert.Equal(t, 2, passErr.TestCase.ExampleNum)
+}
+
+func TestSpecCheck_sentinel_errors(t *testing.T) {
+	t.Parallel()
+
+	dummyFunc := func(string) (string, error) {
+		return "", nil
+	}
+
+	err := SpecCheck("version Unknown", dummyFunc)
+	require.ErrorIs(t, err, ErrInvalidVersion)
+
+	err = SpecCheck("v0.1", dummyFunc)
+	require.ErrorIs(t, err, ErrSpecNotFound)
+
+	_, err = RunSpec("v0.1", dummyFunc)
+	require.ErrorIs(t, err, ErrSpecNotFound)
+}
+
+// ----------------------------------------------------------------------------
+//  Joined errors
+// ----------------------------------------------------------------------------
+
+func TestSpecCheck_joined_errors(t *testing.T) {
+	t.Parallel()
+
+	failingExamples := []int{1, 5, 20, 300}
+
+	testCases, expectedResults := prepareTestCasesMap(t, "spec_v0.30.json")
+
+	failingMarkdowns := map[string]bool{}
+
+	for _, testCase := range testCases {
+		for _, num := range failingExamples {
+			if testCase.ExampleNum == num {
+				failingMarkdowns[testCase.Markdown] = true
+			}
+		}
+	}
+
+	myFunc := func(markdown string) (string, error) {
+		if failingMarkdowns[markdown] {
+			return "<p>bad HTML</p>", nil
+		}
+
+		return expectedResults[markdown], nil
+	}
+
+	// Concurrent run returns all the failures in spec order
+	err := SpecCheckWithConcurrency("v0.30", myFunc, 4)
+	require.Error(t, err)
+
+	var joined interface{ Unwrap() []error }
+
+	require.ErrorAs(t, err, &joined)
+
+	errs := joined.Unwrap()
+	require.Len(t, errs, len(failingExamples), "every failure should be returned")
+
+	for index, num := range failingExamples {
+		var mismatchErr *MismatchError
+
+		require.ErrorAs(t, errs[index], &mismatchErr)
+		assert.Equal(t, num, mismatchErr.TestCase.ExampleNum)
+	}
+
+	// Sequential run stops at the first failure
+	err = SpecCheckWithConcurrency("v0.30", myFunc, -1)
+	require.Error(t, err)
+	require.NotErrorAs(t, err, &joined)
+
+	var mismatchErr *MismatchError
+
+	require.ErrorAs(t, err, &mismatchErr)
+	assert.Equal(t, failingExamples[0], mismatchErr.TestCase.ExampleNum)
+}
+
+func Test_joinErrors(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, joinErrors(nil))
+	require.NoError(t, joinErrors([]error{nil, nil}))
+
+	err := joinErrors([]error{nil, errors.New("foo"), nil, errors.New("bar")})
+	require.EqualError(t, err, "foo\nbar")
+}
+
+// ----------------------------------------------------------------------------
+//  Panic isolation
+// ----------------------------------------------------------------------------
+
+func TestSpecCheck_panic_error(t *testing.T) {
+	t.Parallel()
+
+	golden := getGoldenParser(t, "v0.30")
+	panickingMarkdown := getTestCase(t, "spec_v0.30.json", 5).Markdown
+
+	myFunc := func(markdown string) (string, error) {
+		if markdown == panickingMarkdown {
+			panic("boom")
+		}
+
+		return golden(markdown)
+	}
+
+	for _, opts := range [][]Option{
+		{WithConcurrency(-1)},
+		{WithConcurrency(0)},
+		{WithTimeout(time.Minute)},
+	} {
+		err := SpecCheck("v0.30", myFunc, append(opts, WithExampleRange(1, 10))...)
+		require.Error(t, err)
+
+		var panicErr *PanicError
+
+		require.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, 5, panicErr.TestCase.ExampleNum)
+		assert.Equal(t, "boom", panicErr.Value)
+		assert.Contains(t, panicErr.Stack, "TestSpecCheck_panic_error", "stack should point to the panic")
+		assert.True(t, strings.HasPrefix(panicErr.Error(),
+			"error 5_Tabs: the given function panicked: boom\n"+
+				"given markdown: \"- foo\\n\\n\\t\\tbar\\n\"\n"+
+				"stack trace:\n"),
+		)
+		require.NoError(t, panicErr.Unwrap(), "non-error panic value should not be unwrapped")
+
+		report, err := RunSpec("v0.30", myFunc, append(opts, WithExampleRange(1, 10))...)
+		require.NoError(t, err, "panic should be recorded in the report")
+
+		assert.Equal(t, 9, report.Passed)
+		assert.Equal(t, 1, report.Panicked)
+		assert.Equal(t, 1, report.Sections()[0].Panicked)
+		assert.Equal(t, StatusPanic, report.Results[4].Status)
+		assert.Equal(t, "panic", report.Results[4].Status.String())
+		require.ErrorAs(t, report.Results[4].Err, &panicErr)
+	}
+}
+
+func TestSpecCheck_panic_error_value(t *testing.T) {
+	t.Parallel()
+
+	errForced := errors.New("forced error")
+
+	err := SpecCheck("v0.30", func(string) (string, error) {
+		panic(errForced)
+	}, WithExamples(1))
+
+	require.ErrorIs(t, err, errForced, "error panic value should be unwrapped")
+}
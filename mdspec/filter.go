@@ -0,0 +1,133 @@
+package mdspec
+
+import (
+	"regexp"
+	"slices"
+)
+
+// filter holds the conditions to select the test cases to run.
+//
+// Conditions of the same kind are combined with OR, and conditions of different
+// kinds with AND. For example, WithSections("Tabs") and WithSectionRegexp(re)
+// select the test cases in "Tabs" OR in the sections matching "re", and then
+// WithExampleRange narrows them down to the examples in the range.
+type filter struct {
+	// sections is the list of section names to include.
+	sections []string
+	// sectionRegexps is the list of patterns of section names to include.
+	sectionRegexps []*regexp.Regexp
+	// examples is the list of example numbers to include.
+	examples []int
+	// exampleRanges is the list of example number ranges to include.
+	exampleRanges [][2]int
+	// excluded is the list of example numbers to exclude.
+	excluded []int
+}
+
+// WithSections selects the test cases of the given spec sections. The names
+// must match exactly. E.g. "ATX headings".
+func WithSections(names ...string) Option {
+	return func(conf *config) {
+		conf.filter.sections = append(conf.filter.sections, names...)
+	}
+}
+
+// WithSectionRegexp selects the test cases whose section name matches the given
+// regular expression.
+func WithSectionRegexp(pattern *regexp.Regexp) Option {
+	return func(conf *config) {
+		if pattern != nil {
+			conf.filter.sectionRegexps = append(conf.filter.sectionRegexps, pattern)
+		}
+	}
+}
+
+// WithExamples selects the test cases of the given example numbers.
+func WithExamples(exampleNums ...int) Option {
+	return func(conf *config) {
+		conf.filter.examples = append(conf.filter.examples, exampleNums...)
+	}
+}
+
+// WithExampleRange selects the test cases whose example number is between
+// "first" and "last", inclusive.
+func WithExampleRange(first, last int) Option {
+	return func(conf *config) {
+		conf.filter.exampleRanges = append(conf.filter.exampleRanges, [2]int{first, last})
+	}
+}
+
+// WithoutExamples excludes the test cases of the given example numbers. It takes
+// precedence over the other filters.
+func WithoutExamples(exampleNums ...int) Option {
+	return func(conf *config) {
+		conf.filter.excluded = append(conf.filter.excluded, exampleNums...)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// selectTestCases returns a copy of the test cases that match the filter of the
+// config.
+func (conf *config) selectTestCases(testCases []TestCase) []TestCase {
+	selected := make([]TestCase, 0, len(testCases))
+
+	for _, testCase := range testCases {
+		if conf.filter.match(testCase) {
+			selected = append(selected, testCase)
+		}
+	}
+
+	return selected
+}
+
+// match returns true if the test case matches the filter.
+func (f *filter) match(testCase TestCase) bool {
+	if slices.Contains(f.excluded, testCase.ExampleNum) {
+		return false
+	}
+
+	return f.matchSection(testCase.Section) && f.matchExample(testCase.ExampleNum)
+}
+
+// matchExample returns true if the example number matches any of the example
+// conditions or if there are none.
+func (f *filter) matchExample(exampleNum int) bool {
+	if len(f.examples) == 0 && len(f.exampleRanges) == 0 {
+		return true
+	}
+
+	if slices.Contains(f.examples, exampleNum) {
+		return true
+	}
+
+	for _, exampleRange := range f.exampleRanges {
+		if exampleRange[0] <= exampleNum && exampleNum <= exampleRange[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchSection returns true if the section name matches any of the section
+// conditions or if there are none.
+func (f *filter) matchSection(section string) bool {
+	if len(f.sections) == 0 && len(f.sectionRegexps) == 0 {
+		return true
+	}
+
+	if slices.Contains(f.sections, section) {
+		return true
+	}
+
+	for _, pattern := range f.sectionRegexps {
+		if pattern.MatchString(section) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,143 @@
+package mdspec
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  Filter options with SpecCheck() and RunSpec()
+// ----------------------------------------------------------------------------
+
+func TestSpecCheck_with_sections(t *testing.T) {
+	t.Parallel()
+
+	testCases, expectedResults := prepareTestCasesMap(t, "spec_v0.30.json")
+
+	// Function that only knows the answers of "ATX headings"
+	answers := map[string]string{}
+
+	for _, testCase := range testCases {
+		if testCase.Section == "ATX headings" {
+			answers[testCase.Markdown] = expectedResults[testCase.Markdown]
+		}
+	}
+
+	myFunc := func(markdown string) (string, error) {
+		html, ok := answers[markdown]
+		if !ok {
+			return "", errors.New("not implemented")
+		}
+
+		return html, nil
+	}
+
+	require.Error(t, SpecCheck("v0.30", myFunc), "unfiltered check should fail")
+	require.NoError(t, SpecCheck("v0.30", myFunc, WithSections("ATX headings")))
+	require.NoError(t, SpecCheckWithConcurrency("v0.30", myFunc, -1, WithSections("ATX headings")))
+	require.NoError(t, SpecCheck("v0.30", myFunc, WithSectionRegexp(regexp.MustCompile(`^ATX`))))
+	require.Error(t, SpecCheck("v0.30", myFunc, WithSections("ATX headings", "Tabs")))
+}
+
+func TestRunSpec_with_filters(t *testing.T) {
+	t.Parallel()
+
+	golden := getGoldenParser(t, "v0.30")
+
+	for _, test := range []struct {
+		name     string
+		opts     []Option
+		expected []int
+	}{
+		{
+			name:     "example numbers",
+			opts:     []Option{WithExamples(3, 1, 650)},
+			expected: []int{1, 3, 650},
+		},
+		{
+			name:     "example range",
+			opts:     []Option{WithExampleRange(10, 13)},
+			expected: []int{10, 11, 12, 13},
+		},
+		{
+			name:     "union of example conditions",
+			opts:     []Option{WithExampleRange(1, 2), WithExamples(5), WithExampleRange(7, 7)},
+			expected: []int{1, 2, 5, 7},
+		},
+		{
+			name:     "excluded examples",
+			opts:     []Option{WithExampleRange(1, 5), WithoutExamples(2, 4)},
+			expected: []int{1, 3, 5},
+		},
+		{
+			name:     "section and example range",
+			opts:     []Option{WithSections("Tabs"), WithExampleRange(10, 20)},
+			expected: []int{10, 11},
+		},
+		{
+			name: "section name or pattern",
+			opts: []Option{
+				WithSections("Tabs"),
+				WithSectionRegexp(regexp.MustCompile(`^Backslash`)),
+				WithSectionRegexp(nil),
+				WithoutExamples(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24),
+			},
+			expected: []int{11, 12},
+		},
+	} {
+		report, err := RunSpec("v0.30", golden, test.opts...)
+		require.NoError(t, err, test.name)
+
+		actual := []int{}
+		for _, result := range report.Results {
+			actual = append(actual, result.TestCase.ExampleNum)
+		}
+
+		assert.Equal(t, test.expected, actual, test.name)
+		assert.Equal(t, len(test.expected), report.Passed, test.name)
+	}
+}
+
+func TestRunSpec_no_match(t *testing.T) {
+	t.Parallel()
+
+	dummyFunc := func(string) (string, error) {
+		return "", nil
+	}
+
+	report, err := RunSpec("v0.30", dummyFunc, WithSections("Unknown section"))
+
+	require.ErrorIs(t, err, ErrNoTestCases, "filters matching nothing should be an error")
+	require.Nil(t, report)
+	assert.Contains(t, err.Error(), "spec v0.30: no test cases match the given filters")
+
+	err = SpecCheck("v0.30", dummyFunc, WithExamples(99999))
+
+	require.ErrorIs(t, err, ErrNoTestCases)
+}
+
+// ----------------------------------------------------------------------------
+//  filter.match()
+// ----------------------------------------------------------------------------
+
+func Test_filter_match_no_conditions(t *testing.T) {
+	t.Parallel()
+
+	conf := newConfig(nil)
+
+	require.True(t, conf.filter.match(TestCase{Section: "Tabs", ExampleNum: 1}),
+		"no condition should match everything")
+}
+
+func Test_filter_match_nil_option(t *testing.T) {
+	t.Parallel()
+
+	conf := newConfig([]Option{nil, WithExamples(1)})
+
+	require.True(t, conf.filter.match(TestCase{ExampleNum: 1}), "nil option should be ignored")
+	require.False(t, conf.filter.match(TestCase{ExampleNum: 2}))
+}
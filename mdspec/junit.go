@@ -0,0 +1,220 @@
+package mdspec
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// WriteJUnit writes the report to "w" in the JUnit XML format, which most CI
+// systems can show per test case.
+//
+// Each spec section becomes a <testsuite> and each example a <testcase> named
+// such as "example_1". Mismatches are reported as <failure> and errors, timeouts
+// and panics as <error>, whose bodies hold the markdown, the expected HTML and
+// the actual HTML. Known failures that failed are reported as <skipped> and the
+// ones that passed as <failure>.
+//
+// Usage:
+//
+//	report, err := mdspec.RunSpec("latest", myFunc)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	if err := report.WriteJUnit(os.Stdout); err != nil {
+//		log.Fatal(err)
+//	}
+func (r *Report) WriteJUnit(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Wrap(err, "failed to write JUnit XML")
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(newJUnitSuites(r)); err != nil {
+		return errors.Wrap(err, "failed to write JUnit XML")
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return errors.Wrap(err, "failed to write JUnit XML")
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// junitSuites is the <testsuites> element of JUnit XML.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+}
+
+// junitSuite is the <testsuite> element of JUnit XML for a spec section.
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Cases    []junitCase `xml:"testcase"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+}
+
+// junitCase is the <testcase> element of JUnit XML for an example.
+type junitCase struct {
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitProblem `xml:"skipped,omitempty"`
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+}
+
+// junitProblem is the <failure>, <error> or <skipped> element of JUnit XML.
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",cdata"`
+}
+
+// newJUnitSuites converts the report into the JUnit XML elements.
+func newJUnitSuites(report *Report) junitSuites {
+	suites := junitSuites{
+		Name:   "CommonMark spec " + report.Version,
+		Suites: []junitSuite{},
+	}
+	indexes := map[string]int{}
+
+	for _, result := range report.Results {
+		index, ok := indexes[result.TestCase.Section]
+		if !ok {
+			index = len(suites.Suites)
+			indexes[result.TestCase.Section] = index
+
+			suites.Suites = append(suites.Suites, junitSuite{Name: result.TestCase.Section})
+		}
+
+		suite := &suites.Suites[index]
+		testCase := newJUnitCase(result)
+
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+
+		switch {
+		case testCase.Failure != nil:
+			suite.Failures++
+		case testCase.Error != nil:
+			suite.Errors++
+		case testCase.Skipped != nil:
+			suite.Skipped++
+		}
+	}
+
+	for _, suite := range suites.Suites {
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+	}
+
+	return suites
+}
+
+// newJUnitCase converts the result of an example into a <testcase> element.
+func newJUnitCase(result Result) junitCase {
+	testCase := junitCase{
+		Name:      fmt.Sprintf("example_%d", result.TestCase.ExampleNum),
+		ClassName: result.TestCase.Section,
+	}
+
+	switch {
+	case result.KnownFailure && result.Passed():
+		testCase.Failure = &junitProblem{
+			Message: "listed as a known failure but passed",
+			Type:    "unexpected pass",
+			Body:    junitBody(result),
+		}
+	case result.Passed():
+	case result.KnownFailure:
+		testCase.Skipped = &junitProblem{
+			Message: "known failure: " + result.Status.String(),
+		}
+	case result.Status == StatusFail:
+		testCase.Failure = &junitProblem{
+			Message: "the HTML does not match the expected one",
+			Type:    result.Status.String(),
+			Body:    junitBody(result),
+		}
+	default:
+		testCase.Error = &junitProblem{
+			Message: firstLine(errMessage(result)),
+			Type:    result.Status.String(),
+			Body:    junitBody(result),
+		}
+	}
+
+	return testCase
+}
+
+// errMessage returns the message of the error of the result. A report read by
+// ReadReport has no error if the message was empty, so it falls back to the
+// name of the status.
+func errMessage(result Result) string {
+	if result.Err == nil || result.Err.Error() == "" {
+		return result.Status.String()
+	}
+
+	return result.Err.Error()
+}
+
+// junitBody returns the body of a <failure> or <error> element.
+func junitBody(result Result) string {
+	var body strings.Builder
+
+	if result.Err != nil {
+		body.WriteString(result.Err.Error() + "\n\n")
+	}
+
+	if result.Detail != "" {
+		body.WriteString(result.Detail + "\n\n")
+	}
+
+	body.WriteString("markdown:\n" + result.TestCase.Markdown + "\n")
+	body.WriteString("expected HTML:\n" + result.TestCase.HTML + "\n")
+	body.WriteString("actual HTML:\n" + result.Actual)
+
+	return toXMLChars(body.String())
+}
+
+// toXMLChars replaces the characters not allowed in XML 1.0, such as control
+// characters, with U+FFFD so that the body of a CDATA section is valid.
+func toXMLChars(text string) string {
+	return strings.Map(func(char rune) rune {
+		switch {
+		case char == '\t', char == '\n', char == '\r',
+			char >= 0x20 && char <= 0xD7FF,
+			char >= 0xE000 && char <= 0xFFFD,
+			char >= 0x10000 && char <= 0x10FFFF:
+			return char
+		}
+
+		return unicode.ReplacementChar
+	}, text)
+}
+
+// firstLine returns the first line of the text.
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+
+	return line
+}
@@ -0,0 +1,222 @@
+package mdspec
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  (*Report).WriteJUnit()
+// ----------------------------------------------------------------------------
+
+func TestReport_WriteJUnit(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[int]TestCase{}
+	for _, exampleNum := range []int{1, 2, 3, 4, 5, 6, 12} {
+		testCases[exampleNum] = getTestCase(t, "spec_v0.31.2.json", exampleNum)
+	}
+
+	myFunc := func(markdown string) (string, error) {
+		switch markdown {
+		case testCases[2].Markdown, testCases[5].Markdown:
+			return "<p>bad</p>\n", nil
+		case testCases[3].Markdown:
+			return "", errors.New("unsupported syntax")
+		case testCases[4].Markdown:
+			panic("boom")
+		}
+
+		for _, testCase := range testCases {
+			if testCase.Markdown == markdown {
+				return testCase.HTML, nil
+			}
+		}
+
+		return "", nil
+	}
+
+	report, err := RunSpec("v0.31.2", myFunc,
+		WithExamples(1, 2, 3, 4, 5, 6, 12),
+		WithKnownFailures(5, 6),
+	)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, report.WriteJUnit(&buf))
+	require.True(t, strings.HasPrefix(buf.String(), xml.Header), "it should begin with the XML header")
+
+	var suites junitSuites
+
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites), "it should be a valid XML")
+
+	assert.Equal(t, "CommonMark spec v0.31.2", suites.Name)
+	assert.Equal(t, 7, suites.Tests)
+	assert.Equal(t, 2, suites.Failures, "mismatch and unexpected pass")
+	assert.Equal(t, 2, suites.Errors, "error and panic")
+	assert.Equal(t, 1, suites.Skipped, "known failure")
+
+	require.Len(t, suites.Suites, 2, "one suite per section")
+
+	tabs := suites.Suites[0]
+
+	assert.Equal(t, "Tabs", tabs.Name)
+	assert.Equal(t, 6, tabs.Tests)
+	assert.Equal(t, 2, tabs.Failures)
+	assert.Equal(t, 2, tabs.Errors)
+	assert.Equal(t, 1, tabs.Skipped)
+	require.Len(t, tabs.Cases, 6)
+
+	// Example 1: passed
+	assert.Equal(t, junitCase{Name: "example_1", ClassName: "Tabs"}, tabs.Cases[0])
+
+	// Example 2: mismatch
+	require.NotNil(t, tabs.Cases[1].Failure)
+	assert.Equal(t, "fail", tabs.Cases[1].Failure.Type)
+	assert.Equal(t, ""+
+		"markdown:\n"+testCases[2].Markdown+"\n"+
+		"expected HTML:\n"+testCases[2].HTML+"\n"+
+		"actual HTML:\n<p>bad</p>\n",
+		tabs.Cases[1].Failure.Body)
+
+	// Example 3: error
+	require.NotNil(t, tabs.Cases[2].Error)
+	assert.Equal(t, "error", tabs.Cases[2].Error.Type)
+	assert.Contains(t, tabs.Cases[2].Error.Message, "unsupported syntax")
+	assert.NotContains(t, tabs.Cases[2].Error.Message, "\n", "message should be a single line")
+	assert.Contains(t, tabs.Cases[2].Error.Body, "markdown:\n"+testCases[3].Markdown)
+
+	// Example 4: panic
+	require.NotNil(t, tabs.Cases[3].Error)
+	assert.Equal(t, "panic", tabs.Cases[3].Error.Type)
+	assert.Contains(t, tabs.Cases[3].Error.Body, "stack trace:")
+
+	// Example 5: known failure
+	require.NotNil(t, tabs.Cases[4].Skipped)
+	assert.Equal(t, "known failure: fail", tabs.Cases[4].Skipped.Message)
+
+	// Example 6: unexpected pass
+	require.NotNil(t, tabs.Cases[5].Failure)
+	assert.Equal(t, "unexpected pass", tabs.Cases[5].Failure.Type)
+
+	assert.Equal(t, "Backslash escapes", suites.Suites[1].Name)
+	assert.Equal(t, 1, suites.Suites[1].Tests)
+	assert.Equal(t, "Backslash escapes", suites.Suites[1].Cases[0].ClassName)
+}
+
+func TestReport_WriteJUnit_detail(t *testing.T) {
+	t.Parallel()
+
+	report, err := RunSpec("v0.31.2", func(string) (string, error) {
+		return "<p>bad</p>\n", nil
+	}, WithExamples(1), WithNormalizedHTML())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, report.WriteJUnit(&buf))
+	assert.Contains(t, buf.String(), "normalized HTML differs:\n")
+}
+
+func TestReport_WriteJUnit_invalid_chars(t *testing.T) {
+	t.Parallel()
+
+	report, err := RunSpec("v0.31.2", func(string) (string, error) {
+		return "<p>\x00\x1b]]></p>\n", nil
+	}, WithExamples(1))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, report.WriteJUnit(&buf))
+
+	var suites junitSuites
+
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites), "it should be a valid XML")
+	assert.Contains(t, suites.Suites[0].Cases[0].Failure.Body, "actual HTML:\n<p>\uFFFD\uFFFD]]></p>\n")
+}
+
+func TestReport_WriteJUnit_no_error_message(t *testing.T) {
+	t.Parallel()
+
+	report, err := RunSpec("v0.31.2", func(string) (string, error) {
+		return "", errors.New("")
+	}, WithExamples(1))
+	require.NoError(t, err)
+
+	// The empty error message is omitted in JSON and read back as a nil error
+	var jsonReport bytes.Buffer
+
+	require.NoError(t, report.WriteJSON(&jsonReport))
+
+	readReport, err := ReadReport(&jsonReport)
+	require.NoError(t, err)
+	require.NoError(t, readReport.Results[0].Err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, readReport.WriteJUnit(&buf))
+
+	var suites junitSuites
+
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	assert.Equal(t, "error", suites.Suites[0].Cases[0].Error.Message, "it should fall back to the status")
+}
+
+func TestReport_WriteJUnit_empty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, (&Report{Version: "v0.31.2"}).WriteJUnit(&buf))
+	assert.Equal(t, xml.Header+
+		`<testsuites name="CommonMark spec v0.31.2" tests="0" failures="0" errors="0" skipped="0"></testsuites>`+"\n",
+		buf.String())
+}
+
+func TestReport_WriteJUnit_write_error(t *testing.T) {
+	t.Parallel()
+
+	report := &Report{Version: "v0.31.2"}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, report.WriteJUnit(&buf))
+
+	// Fail at the header, the body and the trailing newline respectively
+	for _, limit := range []int{0, len(xml.Header), buf.Len() - 1} {
+		err := report.WriteJUnit(&limitedWriter{limit: limit})
+
+		require.Error(t, err, "limit: %d", limit)
+		assert.Contains(t, err.Error(), "failed to write JUnit XML")
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Helper functions
+// ----------------------------------------------------------------------------
+
+// limitedWriter is an io.Writer that fails once more than "limit" bytes are
+// written.
+type limitedWriter struct {
+	limit   int
+	written int
+}
+
+func (w *limitedWriter) Write(data []byte) (int, error) {
+	if w.written+len(data) > w.limit {
+		return 0, io.ErrShortWrite
+	}
+
+	w.written += len(data)
+
+	return len(data), nil
+}
@@ -0,0 +1,130 @@
+package mdspec
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// WithKnownFailures sets the example numbers that are known to fail.
+//
+// Failures of these examples are treated as expected and do not make SpecCheck
+// fail. On the other hand, if any of them passes, SpecCheck returns an error to
+// tell that the list can be tightened. In the report of RunSpec, the results of
+// these examples are marked as KnownFailure.
+func WithKnownFailures(exampleNums ...int) Option {
+	return func(conf *config) {
+		conf.knownFailures = append(conf.knownFailures, exampleNums...)
+	}
+}
+
+// LoadKnownFailures reads the list of example numbers known to fail from a file.
+// The format is detected by the file extension:
+//
+//   - ".json": an array of numbers. E.g. `[148, 149, 150]`
+//   - ".yaml" or ".yml": a sequence of numbers. E.g. `- 148`
+//   - Others: plain text with one number per line. Empty lines and the text
+//     after "#" are ignored.
+//
+// Usage:
+//
+//	knownFailures, err := mdspec.LoadKnownFailures("testdata/known_failures.txt")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	err = mdspec.SpecCheck("latest", myFunc, mdspec.WithKnownFailures(knownFailures...))
+func LoadKnownFailures(pathFile string) ([]int, error) {
+	data, err := os.ReadFile(pathFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read known failures file")
+	}
+
+	var exampleNums []int
+
+	switch strings.ToLower(filepath.Ext(pathFile)) {
+	case ".json":
+		err = jsonUnmarshal(data, &exampleNums)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &exampleNums)
+	default:
+		exampleNums, err = parseKnownFailuresText(data)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse known failures file: "+pathFile)
+	}
+
+	return exampleNums, nil
+}
+
+// UnexpectedPasses returns the results of the test cases that are listed as
+// known failures but passed. These can be removed from the list.
+func (r *Report) UnexpectedPasses() []Result {
+	passes := []Result{}
+
+	for _, result := range r.Results {
+		if result.KnownFailure && result.Passed() {
+			passes = append(passes, result)
+		}
+	}
+
+	return passes
+}
+
+// UnexpectedFailures returns the results of the test cases that did not pass
+// and are not listed as known failures.
+func (r *Report) UnexpectedFailures() []Result {
+	failures := []Result{}
+
+	for _, result := range r.Results {
+		if !result.KnownFailure && !result.Passed() {
+			failures = append(failures, result)
+		}
+	}
+
+	return failures
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// isKnownFailure returns true if the test case is listed as a known failure.
+func (conf *config) isKnownFailure(testCase TestCase) bool {
+	return slices.Contains(conf.knownFailures, testCase.ExampleNum)
+}
+
+// parseKnownFailuresText parses the plain text format of the known failures.
+func parseKnownFailuresText(data []byte) ([]int, error) {
+	exampleNums := []int{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	numLine := 0
+
+	for scanner.Scan() {
+		numLine++
+
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		exampleNum, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d: invalid example number", numLine)
+		}
+
+		exampleNums = append(exampleNums, exampleNum)
+	}
+
+	return exampleNums, errors.Wrap(scanner.Err(), "failed to scan known failures")
+}
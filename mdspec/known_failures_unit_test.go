@@ -0,0 +1,145 @@
+package mdspec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  WithKnownFailures()
+// ----------------------------------------------------------------------------
+
+func TestSpecCheck_known_failures(t *testing.T) {
+	t.Parallel()
+
+	testCases, expectedResults := prepareTestCasesMap(t, oldestSpecFile)
+
+	// Function that fails on the first two examples
+	myFunc := func(markdown string) (string, error) {
+		if markdown == testCases[0].Markdown || markdown == testCases[1].Markdown {
+			return "<p>deviation</p>", nil
+		}
+
+		return expectedResults[markdown], nil
+	}
+
+	first, second := testCases[0].ExampleNum, testCases[1].ExampleNum
+
+	for _, maxConcurrency := range []int{-1, 0} {
+		err := SpecCheckWithConcurrency("v0.13", myFunc, maxConcurrency)
+		require.Error(t, err, "failures should be reported without the list")
+
+		err = SpecCheckWithConcurrency("v0.13", myFunc, maxConcurrency,
+			WithKnownFailures(first), WithKnownFailures(second))
+		require.NoError(t, err, "known failures should not fail the check")
+
+		err = SpecCheckWithConcurrency("v0.13", myFunc, maxConcurrency, WithKnownFailures(first))
+		require.Error(t, err, "failures not in the list should fail the check")
+		assert.Contains(t, err.Error(), "did not return the expected HTML result")
+
+		err = SpecCheckWithConcurrency("v0.13", myFunc, maxConcurrency,
+			WithKnownFailures(first, second, 100))
+		require.Error(t, err, "known failures that pass should fail the check")
+		assert.Contains(t, err.Error(), "error 100_")
+		assert.Contains(t, err.Error(), "the example is listed as a known failure but passed")
+	}
+}
+
+func TestRunSpec_known_failures(t *testing.T) {
+	t.Parallel()
+
+	testCases, expectedResults := prepareTestCasesMap(t, oldestSpecFile)
+
+	// Function that fails on the first example only
+	myFunc := func(markdown string) (string, error) {
+		if markdown == testCases[0].Markdown {
+			return "<p>deviation</p>", nil
+		}
+
+		return expectedResults[markdown], nil
+	}
+
+	report, err := RunSpec("v0.13", myFunc,
+		WithKnownFailures(testCases[0].ExampleNum, testCases[2].ExampleNum),
+		WithExampleRange(testCases[0].ExampleNum, testCases[3].ExampleNum),
+	)
+	require.NoError(t, err)
+
+	require.Len(t, report.Results, 4)
+	assert.True(t, report.Results[0].KnownFailure)
+	assert.False(t, report.Results[1].KnownFailure)
+	assert.True(t, report.Results[2].KnownFailure)
+	assert.Equal(t, 1, report.Failed, "known failures are still counted as failures")
+	assert.Empty(t, report.UnexpectedFailures())
+
+	unexpectedPasses := report.UnexpectedPasses()
+	require.Len(t, unexpectedPasses, 1)
+	assert.Equal(t, testCases[2].ExampleNum, unexpectedPasses[0].TestCase.ExampleNum)
+
+	report, err = RunSpec("v0.13", myFunc, WithExampleRange(testCases[0].ExampleNum, testCases[3].ExampleNum))
+	require.NoError(t, err)
+
+	assert.Empty(t, report.UnexpectedPasses())
+	require.Len(t, report.UnexpectedFailures(), 1)
+	assert.Equal(t, testCases[0].ExampleNum, report.UnexpectedFailures()[0].TestCase.ExampleNum)
+}
+
+// ----------------------------------------------------------------------------
+//  LoadKnownFailures()
+// ----------------------------------------------------------------------------
+
+func TestLoadKnownFailures(t *testing.T) {
+	t.Parallel()
+
+	for nameFile, content := range map[string]string{
+		"known.json": "[148, 149, 150]",
+		"known.yaml": "- 148\n- 149 # comment\n- 150\n",
+		"known.YML":  "[148, 149, 150]",
+		"known.txt":  "# Raw HTML deviations\n148\n\n  149  # trailing comment\n150",
+		"known":      "148\n149\n150\n",
+	} {
+		pathFile := filepath.Join(t.TempDir(), nameFile)
+		require.NoError(t, os.WriteFile(pathFile, []byte(content), 0o600))
+
+		exampleNums, err := LoadKnownFailures(pathFile)
+		require.NoError(t, err, nameFile)
+		require.Equal(t, []int{148, 149, 150}, exampleNums, nameFile)
+	}
+}
+
+func TestLoadKnownFailures_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := LoadKnownFailures(filepath.Join(t.TempDir(), "unknown.txt"))
+
+	require.Error(t, err, "missing file should be an error")
+	assert.Contains(t, err.Error(), "failed to read known failures file")
+
+	for nameFile, content := range map[string]string{
+		"bad.json": `["148"]`,
+		"bad.yaml": "- foo\n",
+		"bad.txt":  "148\nfoo\n",
+	} {
+		pathFile := filepath.Join(t.TempDir(), nameFile)
+		require.NoError(t, os.WriteFile(pathFile, []byte(content), 0o600))
+
+		exampleNums, err := LoadKnownFailures(pathFile)
+
+		require.Error(t, err, nameFile)
+		require.Nil(t, exampleNums, nameFile)
+		assert.Contains(t, err.Error(), "failed to parse known failures file", nameFile)
+	}
+}
+
+func Test_parseKnownFailuresText_line_number(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseKnownFailuresText([]byte("1\n\n# comment\n1-3\n"))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 4: invalid example number")
+}
@@ -10,13 +10,12 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"path/filepath"
-	"runtime"
-	"slices"
+	"strings"
 
 	"github.com/pkg/errors"
 	"golang.org/x/mod/semver"
-	"golang.org/x/sync/errgroup"
 )
 
 // Embed JSON files under _spec into the binary.
@@ -28,7 +27,6 @@ var (
 	nameDirSpecs     = "_specs"
 	nameFileSpecList = "spec_list.json"
 	prefixFileSpec   = "spec_"
-	versionList      []string
 )
 
 const (
@@ -36,12 +34,16 @@ const (
 	// for test execution. A value of 0 uses runtime.GOMAXPROCS(0), whose behavior may
 	// depend on the Go version and environment. See Go release notes for details.
 	defaultConcurrency = 0
+	// noConcurrency is the concurrency value to run the tests sequentially.
+	noConcurrency = -1
 )
 
 // Variables to be mocked/monkey-patched during testing.
 var (
 	// jsonUnmarshal is a copy of json.Unmarshal to ease testing.
 	jsonUnmarshal = json.Unmarshal
+	// specFS is the file system of the spec files to ease testing.
+	specFS fs.FS = specFiles
 )
 
 // TestCase represents a single test case from the CommonMark specification.
@@ -52,6 +54,45 @@ type TestCase struct {
 	StartLine  int    `json:"start_line"`
 	EndLine    int    `json:"end_line"`
 	ExampleNum int    `json:"example"`
+	// Extensions is the names of the extensions required by the example, such
+	// as "table" in the GFM spec. It is empty for the CommonMark spec.
+	Extensions ExtensionList `json:"extensions,omitempty"`
+}
+
+// ExtensionList is the space-separated names of extensions, such as
+// "autolink strikethrough". It is a string rather than a slice to keep TestCase
+// comparable. In JSON, it is an array of the names like the output of
+// "spec_tests.py --dump-tests" of cmark-gfm.
+type ExtensionList string
+
+// Names returns the names of the extensions.
+func (l ExtensionList) Names() []string {
+	return strings.Fields(string(l))
+}
+
+// MarshalJSON implements json.Marshaler. It writes the names as an array.
+func (l ExtensionList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.Names()) //nolint:wrapcheck // never fails for strings
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It reads an array of the names. A
+// space-separated string is also accepted.
+func (l *ExtensionList) UnmarshalJSON(data []byte) error {
+	var names []string
+
+	if err := json.Unmarshal(data, &names); err != nil {
+		var text string
+
+		if json.Unmarshal(data, &text) != nil {
+			return errors.Wrap(err, "extensions should be an array of strings")
+		}
+
+		names = strings.Fields(text)
+	}
+
+	*l = ExtensionList(strings.Join(names, " "))
+
+	return nil
 }
 
 // ----------------------------------------------------------------------------
@@ -61,11 +102,24 @@ type TestCase struct {
 // SpecCheck checks if "yourFunc" complies with the specified CommonMark version
 // specification using official test cases.
 //
+// Options such as WithSections can be given to check only a subset of the test
+// cases.
+//
+// The failures are returned as *MismatchError, *FuncError, *TimeoutError or
+// *PanicError, which can be inspected with errors.As. A panic in "yourFunc" is
+// recovered and reported as a *PanicError of the example. When the tests run
+// concurrently (default), the returned error joins the failures of all the test
+// cases in spec order. For an invalid or unsupported spec version, the error
+// wraps ErrInvalidVersion or ErrSpecNotFound.
+//
+// To fail only on regressions from a previous run, use WithBaseline. To fail
+// only if the pass rates are below thresholds, use WithThresholds.
+//
 // Usage:
 //
 //	err := mdspec.SpecCheck("v1.14", myFunc)
-func SpecCheck(specVersion string, yourFunc func(string) (string, error)) error {
-	return SpecCheckWithConcurrency(specVersion, yourFunc, defaultConcurrency)
+func SpecCheck(specVersion string, yourFunc func(string) (string, error), opts ...Option) error {
+	return specCheck(context.Background(), specVersion, toParseFunc(yourFunc), newConfig(opts))
 }
 
 // SpecCheckWithConcurrency is the same as SpecCheck but allows specifying the maximum
@@ -77,49 +131,16 @@ func SpecCheck(specVersion string, yourFunc func(string) (string, error)) error
 // If your function is lightning fast (< 5μs/call), running tests concurrently may not
 // yield performance benefits due to overhead of preparing goroutines and context switching.
 // In such cases, consider using "maxConcurrency = -1" to run tests sequentially.
-func SpecCheckWithConcurrency(specVersion string, yourFunc func(string) (string, error), maxConcurrency int) error {
-	const noConcurrency = -1
-
-	if !isValidFormatVer(specVersion) {
-		return errors.Errorf(
-			"invalid spec version format: %s, it should be like 'v0.14'", specVersion)
-	}
-
-	if specVersion == "latest" {
-		latestVer, err := LatestVersion()
-		if err != nil {
-			return errors.Wrap(err, "failed to get latest spec version")
-		}
-
-		specVersion = latestVer
-	}
-
-	nameFileSpec := fmt.Sprintf("%s%s.json", prefixFileSpec, specVersion)
-
-	jsonSpec, err := loadFile(nameFileSpec)
-	if err != nil {
-		return errors.Wrap(err, "spec file not found: "+nameFileSpec)
-	}
-
-	var testCases []TestCase
-
-	err = jsonUnmarshal(jsonSpec, &testCases)
-	if err != nil {
-		return errors.Wrap(err, "failed to parse list of supported spec versions")
-	}
-
-	if maxConcurrency == noConcurrency {
-		for _, testCase := range testCases {
-			err = runSingleTest(testCase, yourFunc)
-			if err != nil {
-				return errors.Wrap(err, "test failed")
-			}
-		}
-
-		return nil
-	}
-
-	return runTestsConcurrently(testCases, yourFunc, maxConcurrency)
+// Note that the sequential run stops at the first failure.
+//
+// The "maxConcurrency" argument takes precedence over WithConcurrency option.
+func SpecCheckWithConcurrency(
+	specVersion string, yourFunc func(string) (string, error), maxConcurrency int, opts ...Option,
+) error {
+	conf := newConfig(opts)
+	conf.maxConcurrency = maxConcurrency
+
+	return specCheck(context.Background(), specVersion, toParseFunc(yourFunc), conf)
 }
 
 // LatestVersion returns the latest available version of the specification.
@@ -132,45 +153,65 @@ func LatestVersion() (string, error) {
 	return versions[len(versions)-1], nil
 }
 
-// ListVersion returns a list of all available versions of the specification.
+// ListVersion returns a list of all available versions of the specification in
+// ascending order of semantic versioning. See CompareVersions.
+//
+// The returned slice is a copy and it is safe to call from multiple goroutines.
 func ListVersion() ([]string, error) {
-	// Cache the version list
-	if versionList != nil {
-		return versionList, nil
+	specInfos, err := Versions()
+	if err != nil {
+		return nil, err
 	}
 
-	jsonList, err := loadFile(nameFileSpecList)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to read list of supported spec versions")
+	versions := make([]string, len(specInfos))
+
+	for i, specInfo := range specInfos {
+		versions[i] = specInfo.Version
 	}
 
-	// Temporary struct to unmarshal the list of supported spec versions
-	objList := []struct {
-		Version       string `json:"version"`
-		URL           string `json:"url"`
-		DateEnactment string `json:"date"`
-	}{}
+	return versions, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
 
-	err = jsonUnmarshal(jsonList, &objList)
+// specCheck runs the test cases selected by the config. When the tests run
+// concurrently, it returns the joined errors of all the failed test cases in
+// spec order. When they run sequentially, it returns the first error. In the
+// threshold mode, it runs all of them and returns the *ThresholdError if any.
+func specCheck(ctx context.Context, specVersion string, yourFunc parseFunc, conf *config) error {
+	resolvedVer, testCases, err := loadSelectedTestCases(specVersion, conf)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to parse list of supported spec versions")
+		return err
 	}
 
-	// Create list of supported spec versions
-	versionList = make([]string, len(objList))
+	if len(conf.thresholds) > 0 {
+		results := runAllTests(ctx, testCases, yourFunc, conf)
+		if ctx.Err() != nil {
+			return errCanceled(ctx)
+		}
 
-	for i, obj := range objList {
-		versionList[i] = obj.Version
+		return newReport(resolvedVer, results).CheckThresholds(conf.thresholds...)
 	}
 
-	slices.Sort(versionList)
+	if conf.maxConcurrency == noConcurrency {
+		for _, testCase := range testCases {
+			err = runCheckedTest(ctx, testCase, yourFunc, conf)
+			if ctx.Err() != nil {
+				return errCanceled(ctx)
+			}
 
-	return versionList, nil
-}
+			if err != nil {
+				return errors.Wrap(err, "test failed")
+			}
+		}
 
-// ----------------------------------------------------------------------------
-//  Private functions
-// ----------------------------------------------------------------------------
+		return nil
+	}
+
+	return runTestsConcurrently(ctx, testCases, yourFunc, conf)
+}
 
 // getNamesFile returns a list of all available file names in the embedded
 // filesystem. Note that this function does not recurse into subdirectories.
@@ -181,7 +222,7 @@ func getNamesFile(dir string) ([]string, error) {
 
 	out := []string{}
 
-	entries, err := specFiles.ReadDir(dir)
+	entries, err := fs.ReadDir(specFS, dir)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read directory")
 	}
@@ -206,13 +247,82 @@ func isValidFormatVer(verInput string) bool {
 	return semver.IsValid(verInput)
 }
 
+// loadTestCases returns a copy of the test cases of the given spec version from
+// the embedded filesystem. "latest" is resolved to the latest available version.
+func loadTestCases(specVersion string) ([]TestCase, error) {
+	specVersion, err := resolveVersion(specVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return cachedTestCases(specVersion)
+}
+
+// readTestCases reads and parses the test cases of the resolved spec version
+// from the embedded filesystem.
+func readTestCases(specVersion string) ([]TestCase, error) {
+	nameFileSpec := fmt.Sprintf("%s%s.json", prefixFileSpec, specVersion)
+
+	jsonSpec, err := loadFile(nameFileSpec)
+	if err != nil {
+		return nil, errors.Wrapf(ErrSpecNotFound, "%s: %v", nameFileSpec, err)
+	}
+
+	var testCases []TestCase
+
+	err = jsonUnmarshal(jsonSpec, &testCases)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse list of supported spec versions")
+	}
+
+	return testCases, nil
+}
+
+// loadSelectedTestCases returns the resolved spec version and the test cases of
+// it that match the filters of the config. If the config has a suite, its name
+// and test cases are used instead.
+func loadSelectedTestCases(specVersion string, conf *config) (string, []TestCase, error) {
+	resolvedVer, testCases, err := conf.loadAllTestCases(specVersion)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := conf.checkBaseline(resolvedVer); err != nil {
+		return "", nil, err
+	}
+
+	testCases = conf.selectTestCases(testCases)
+	if len(testCases) == 0 {
+		return "", nil, errors.Wrap(ErrNoTestCases, "spec "+resolvedVer)
+	}
+
+	return resolvedVer, testCases, nil
+}
+
+// loadAllTestCases returns the name and the test cases of the suite of the
+// config, or of the embedded suite of the spec version. See LoadSuite. The test
+// cases are shared and must not be modified.
+func (conf *config) loadAllTestCases(specVersion string) (string, []TestCase, error) {
+	suite := conf.suite
+	if suite == nil {
+		var err error
+
+		suite, err = LoadSuite(specVersion)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	return suite.Name, suite.testCases, nil
+}
+
 // loadFile returns the contents of the file with the given name from the embedded
 // filesystem.
 func loadFile(nameFile string) ([]byte, error) {
 	// Load the list of supported spec versions
 	pathFile := filepath.ToSlash(filepath.Join(nameDirSpecs, nameFile))
 
-	jsonData, err := specFiles.ReadFile(pathFile)
+	jsonData, err := fs.ReadFile(specFS, pathFile)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read file")
 	}
@@ -220,52 +330,97 @@ func loadFile(nameFile string) ([]byte, error) {
 	return jsonData, nil
 }
 
-// runSingleTest executes a single test case using the given function and
-// returns an error if the test fails.
-func runSingleTest(testCase TestCase, yourFunc func(string) (string, error)) error {
-	nameTest := fmt.Sprintf("%d_%s", testCase.ExampleNum, testCase.Section)
-	expect := testCase.HTML
+// resolveVersion validates the format of the given spec version and resolves
+// "latest" and the version constraints to the latest available version. See
+// ResolveVersion for the constraints.
+func resolveVersion(specVersion string) (string, error) {
+	switch {
+	case specVersion == "latest":
+		latestVer, err := LatestVersion()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get latest spec version")
+		}
 
-	actual, err := yourFunc(testCase.Markdown)
-	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf(
-			"error %s: the given function failed to parse markdown.\n"+
-				"given markdown: %#v\nexpect HTML: %#v\nactual HTML: %#v",
-			nameTest, testCase.Markdown, expect, actual,
-		))
+		return latestVer, nil
+	case isValidFormatVer(specVersion):
+		return specVersion, nil
 	}
 
-	if expect != actual {
-		return errors.Errorf(
-			"error %s: the given function did not return the expected HTML result.\n"+
-				"given markdown: %#v\nexpect HTML: %#v\nactual HTML: %#v",
-			nameTest, testCase.Markdown, expect, actual,
-		)
+	return latestMatchingVersion(specVersion)
+}
+
+// runCheckedTest executes a single test case and returns its error. See
+// checkResult.
+func runCheckedTest(ctx context.Context, testCase TestCase, yourFunc parseFunc, conf *config) error {
+	return checkResult(ctx, runTestCase(ctx, testCase, yourFunc, conf), conf)
+}
+
+// checkResult returns the error of the result like resultError but treats the
+// failure of a known failure as expected and its pass as an error. In the
+// regression mode, the failure of an example that did not pass in the baseline
+// is ignored.
+func checkResult(ctx context.Context, result Result, conf *config) error {
+	err := resultError(ctx, result)
+
+	switch {
+	case conf.isKnownFailure(result.TestCase):
+		if err != nil {
+			return nil // expected failure
+		}
+
+		return &UnexpectedPassError{TestCase: result.TestCase}
+	case conf.isBaselineFailure(result.TestCase):
+		return nil // failing or improved since the baseline
 	}
 
-	return nil
+	return err
 }
 
-// runTestsConcurrently runs all test cases concurrently using the given function
-// and returns an error if any test fails.
-func runTestsConcurrently(testCases []TestCase, yourFunc func(string) (string, error), maxConcurrency int) error {
-	// Context is auto-canceled on first error, but we run all tests to completion anyway.
-	errGroup, _ := errgroup.WithContext(context.Background())
+// resultError returns a *FuncError, *MismatchError, *TimeoutError or
+// *PanicError if the test case of the result failed. It returns the error of the
+// context if the function returned an error after the context is done.
+func resultError(ctx context.Context, result Result) error {
+	switch result.Status {
+	case StatusPass:
+		return nil
+	case StatusPanic, StatusTimeout:
+		return result.Err
+	case StatusError:
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return &FuncError{
+			Err:      result.Err,
+			Actual:   result.Actual,
+			TestCase: result.TestCase,
+		}
+	}
 
-	if maxConcurrency == 0 {
-		maxConcurrency = runtime.GOMAXPROCS(0)
+	return &MismatchError{
+		Expected: result.TestCase.HTML,
+		Actual:   result.Actual,
+		Detail:   result.Detail,
+		TestCase: result.TestCase,
 	}
+}
 
-	errGroup.SetLimit(maxConcurrency)
+// runTestsConcurrently runs all test cases concurrently using the given function
+// and returns an error that joins the errors of all the failed tests in spec
+// order. On the cancellation of the context, it stops starting new tests and
+// returns the error of the context.
+func runTestsConcurrently(ctx context.Context, testCases []TestCase, yourFunc parseFunc, conf *config) error {
+	results := runAllTests(ctx, testCases, yourFunc, conf)
+	if ctx.Err() != nil {
+		return errCanceled(ctx)
+	}
 
-	for _, testCase := range testCases {
-		// As of Go 1.22+, loop variables are captured by value in closures.
-		errGroup.Go(func() error {
-			return runSingleTest(testCase, yourFunc)
-		})
+	errs := make([]error, len(results))
+	for index, result := range results {
+		errs[index] = checkResult(ctx, result, conf)
 	}
 
-	err := errGroup.Wait()
+	err := joinErrors(errs)
 	if err != nil {
 		return errors.Wrap(err, "one or more tests failed")
 	}
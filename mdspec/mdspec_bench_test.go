@@ -1,6 +1,7 @@
 package mdspec
 
 import (
+	"bytes"
 	"math/rand/v2"
 	"strconv"
 	"testing"
@@ -99,10 +100,49 @@ func BenchmarkSpecCheck_DefaultBehavior(b *testing.B) {
 	b.ReportMetric(float64(len(testCases)), "testcases")
 }
 
+// BenchmarkSpecCheck_WithSuite benchmarks SpecCheck with the suite loaded once
+// and with the suite loaded from the embedded JSON in every iteration.
+func BenchmarkSpecCheck_WithSuite(b *testing.B) {
+	_, expectedResults := prepareTestCasesMap(b, oldestSpecFile)
+
+	correctFunc := func(markdown string) (string, error) {
+		return expectedResults[markdown], nil
+	}
+
+	b.Run("LoadedOnce", func(b *testing.B) {
+		suite, err := LoadSuite("v0.13")
+		require.NoError(b, err)
+
+		for b.Loop() {
+			err := SpecCheck("", correctFunc, WithSuite(suite), WithConcurrency(-1))
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("DecodedEveryTime", func(b *testing.B) {
+		for b.Loop() {
+			suite, err := ReadSuite("v0.13", bytes.NewReader(mustLoadFile(b, oldestSpecFile)))
+			require.NoError(b, err)
+
+			err = SpecCheck("", correctFunc, WithSuite(suite), WithConcurrency(-1))
+			require.NoError(b, err)
+		}
+	})
+}
+
 // ============================================================================
 //  Helper Functions for Benchmarks
 // ============================================================================
 
+func mustLoadFile(b *testing.B, nameFile string) []byte {
+	b.Helper()
+
+	data, err := loadFile(nameFile)
+	require.NoError(b, err)
+
+	return data
+}
+
 func randomDelay(minMicros, maxMicros int) {
 	//nolint:gosec // weak random is acceptable for benchmarking purposes
 	delay := minMicros + rand.IntN(maxMicros-minMicros+1)
@@ -1,9 +1,12 @@
 package mdspec_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/KEINOS/go-md-spec-check/mdspec"
 )
@@ -22,7 +25,9 @@ func Example() {
 	// version 0.30.
 	err := mdspec.SpecCheck("v0.30", myMarkdownParser)
 	if err != nil {
-		if strings.Contains(err.Error(), "did not return the expected HTML result") {
+		var mismatchErr *mdspec.MismatchError
+
+		if errors.As(err, &mismatchErr) {
 			fmt.Println("The parser does not comply with the CommonMark specification.")
 		}
 	}
@@ -30,14 +35,19 @@ func Example() {
 	// Check if the `myMarkdownParser()` complies with the latest CommonMark specification
 	err = mdspec.SpecCheck("latest", myMarkdownParser)
 	if err != nil {
-		if strings.Contains(err.Error(), "did not return the expected HTML result") {
+		var mismatchErr *mdspec.MismatchError
+
+		if errors.As(err, &mismatchErr) {
 			fmt.Println("The parser does not comply with the CommonMark specification.")
+			fmt.Printf("First failure: example %d (%s)\n",
+				mismatchErr.TestCase.ExampleNum, mismatchErr.TestCase.Section)
 		}
 	}
 
 	// Output:
 	// The parser does not comply with the CommonMark specification.
 	// The parser does not comply with the CommonMark specification.
+	// First failure: example 1 (Tabs)
 }
 
 func ExampleLatestVersion() {
@@ -81,3 +91,243 @@ func ExampleListVersion() {
 	// v0.30
 	// v0.31.2
 }
+
+func ExampleCase() {
+	testCase, err := mdspec.Case("v0.31.2", 1)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Example %d (%s)\n", testCase.ExampleNum, testCase.Section)
+	fmt.Printf("Markdown: %q\n", testCase.Markdown)
+	fmt.Printf("HTML: %q\n", testCase.HTML)
+	// Output:
+	// Example 1 (Tabs)
+	// Markdown: "\tfoo\tbaz\t\tbim\n"
+	// HTML: "<pre><code>foo\tbaz\t\tbim\n</code></pre>\n"
+}
+
+func ExampleSections() {
+	sections, err := mdspec.Sections("v0.31.2")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(len(sections), "sections")
+	fmt.Println(sections[0])
+	fmt.Println(sections[len(sections)-1])
+	// Output:
+	// 26 sections
+	// Tabs
+	// Textual content
+}
+
+func ExampleVersionInfo() {
+	specInfo, err := mdspec.VersionInfo("v0.31.x")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(specInfo)
+	fmt.Println(specInfo.URL)
+	// Output:
+	// CommonMark 0.31.2 (2024-01-28)
+	// https://spec.commonmark.org/0.31.2/spec.json
+}
+
+func ExampleVersionAt() {
+	// The spec version in effect on the date
+	specInfo, err := mdspec.VersionAt(time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(specInfo.Version)
+	// Output: v0.29
+}
+
+func ExampleDiffVersions() {
+	diff, err := mdspec.DiffVersions("v0.30", "v0.31.2")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("added:", diff.Count(mdspec.ChangeAdded))
+	fmt.Println("removed:", diff.Count(mdspec.ChangeRemoved))
+	fmt.Println("modified:", diff.Count(mdspec.ChangeModified))
+	fmt.Println("unchanged:", diff.Unchanged)
+
+	change := diff.Changes[0]
+	fmt.Printf("%s example %d: %q\n", change.Kind, change.New.ExampleNum, change.New.Markdown)
+	// Output:
+	// added: 17
+	// removed: 17
+	// modified: 0
+	// unchanged: 635
+	// added example 20: "<https://example.com?find=\\*>\n"
+}
+
+//nolint:revive // markdown in myMarkdownParser is not used but keeping it for example purposes.
+func ExampleRunSpec() {
+	// Sample Markdown-to-HTML conversion function that does not do its job.
+	myMarkdownParser := func(markdown string) (string, error) {
+		return "<p>Hello, World!</p>\n", nil
+	}
+
+	// Run all the test cases of CommonMark v0.30 and get the report.
+	report, err := mdspec.RunSpec("v0.30", myMarkdownParser)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Spec %s: %d/%d passed (%.1f%%)\n",
+		report.Version, report.Passed, report.Total, report.PassRate())
+	fmt.Println("Failures:", len(report.Failures()))
+
+	// Output:
+	// Spec v0.30: 0/652 passed (0.0%)
+	// Failures: 652
+}
+
+func ExampleReport_Sections() {
+	// Sample Markdown-to-HTML conversion function that only supports indented
+	// code blocks without tabs.
+	myMarkdownParser := func(markdown string) (string, error) {
+		if code, ok := strings.CutPrefix(markdown, "    "); ok && !strings.Contains(markdown, "\t") {
+			return "<pre><code>" + code + "</code></pre>\n", nil
+		}
+
+		return "", nil
+	}
+
+	report, err := mdspec.RunSpec("v0.31.2", myMarkdownParser)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Print the first three sections in spec order
+	for _, section := range report.Sections()[:3] {
+		fmt.Printf("%s: %d/%d (%.1f%%)\n",
+			section.Name, section.Passed, section.Total, section.PassRate())
+	}
+
+	// Output:
+	// Tabs: 0/11 (0.0%)
+	// Backslash escapes: 1/13 (7.7%)
+	// Entity and numeric character references: 0/17 (0.0%)
+}
+
+func ExampleWithSections() {
+	// Sample Markdown-to-HTML conversion function that only supports ATX
+	// headings of level 1.
+	myMarkdownParser := func(markdown string) (string, error) {
+		if heading, ok := strings.CutPrefix(markdown, "# "); ok {
+			return "<h1>" + strings.TrimSpace(heading) + "</h1>\n", nil
+		}
+
+		return "", nil
+	}
+
+	// Check only the examples in the "ATX headings" section
+	report, err := mdspec.RunSpec("v0.31.2", myMarkdownParser,
+		mdspec.WithSections("ATX headings"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%d/%d passed\n", report.Passed, report.Total)
+
+	// Filters can also be used with SpecCheck
+	err = mdspec.SpecCheck("v0.31.2", myMarkdownParser,
+		mdspec.WithSections("ATX headings"),
+		mdspec.WithExamples(67, 75),
+	)
+	fmt.Println("Example 67 and 75 passed:", err == nil)
+
+	// Output:
+	// 2/18 passed
+	// Example 67 and 75 passed: true
+}
+
+func ExampleNormalizeHTML() {
+	fmt.Println(mdspec.NormalizeHTML("<p>a<br />\nb &quot;c&#x22;</p>\n"))
+	fmt.Println(mdspec.NormalizeHTML("<p>a<br/>b &#34;c&quot;</p>"))
+
+	// Output:
+	// <p>a<br>b &quot;c&quot;</p>
+	// <p>a<br>b &quot;c&quot;</p>
+}
+
+func ExampleWithNormalizedHTML() {
+	// Sample Markdown-to-HTML conversion function that renders the hard line
+	// break of example 633 as "<br/>" instead of "<br />".
+	myMarkdownParser := func(string) (string, error) {
+		return "<p>foo<br/>\nbaz</p>\n", nil
+	}
+
+	err := mdspec.SpecCheck("v0.31.2", myMarkdownParser, mdspec.WithExamples(633))
+	fmt.Println("Strict comparison passed:", err == nil)
+
+	err = mdspec.SpecCheck("v0.31.2", myMarkdownParser, mdspec.WithExamples(633),
+		mdspec.WithNormalizedHTML(),
+	)
+	fmt.Println("Normalized comparison passed:", err == nil)
+
+	// Output:
+	// Strict comparison passed: false
+	// Normalized comparison passed: true
+}
+
+func ExampleIgnoreAttributes() {
+	// Sample Markdown-to-HTML conversion function that adds a "rel" attribute
+	// to links, which is not in the spec.
+	myMarkdownParser := func(string) (string, error) {
+		return `<p><a rel="nofollow" href="/uri" title="title">link</a></p>` + "\n", nil
+	}
+
+	// Ignore the "rel" attribute of "<a>" tags and compare the rest after
+	// normalization
+	cmp := mdspec.IgnoreAttributes(mdspec.NormalizedComparator(), "a.rel")
+
+	err := mdspec.SpecCheck("v0.31.2", myMarkdownParser,
+		mdspec.WithExamples(482),
+		mdspec.WithComparator(cmp),
+	)
+	fmt.Println("Passed:", err == nil)
+
+	// Output:
+	// Passed: true
+}
+
+func ExampleSpecCheckContext() {
+	// Sample context-aware Markdown-to-HTML conversion function that hangs on
+	// tabs until the context is done.
+	myMarkdownParser := func(ctx context.Context, markdown string) (string, error) {
+		if strings.Contains(markdown, "\t") {
+			<-ctx.Done()
+
+			return "", ctx.Err()
+		}
+
+		return "<p>" + strings.TrimSpace(markdown) + "</p>\n", nil
+	}
+
+	// Stop the whole run after a minute and each example after 10ms
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err := mdspec.SpecCheckContext(ctx, "v0.31.2", myMarkdownParser,
+		mdspec.WithExamples(1),
+		mdspec.WithTimeout(10*time.Millisecond),
+	)
+
+	var timeoutErr *mdspec.TimeoutError
+
+	if errors.As(err, &timeoutErr) {
+		fmt.Printf("Example %d timed out after %s\n", timeoutErr.TestCase.ExampleNum, timeoutErr.Timeout)
+	}
+
+	// Output:
+	// Example 1 timed out after 10ms
+}
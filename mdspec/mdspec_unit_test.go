@@ -5,6 +5,7 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"runtime"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -17,6 +18,20 @@ import (
 
 const oldestSpecFile = "spec_v0.13.json"
 
+// ----------------------------------------------------------------------------
+//  TestCase
+// ----------------------------------------------------------------------------
+
+func TestTestCase_comparable(t *testing.T) {
+	t.Parallel()
+
+	testCase := TestCase{ExampleNum: 1, Extensions: "table"}
+	seen := map[TestCase]bool{testCase: true}
+
+	require.True(t, seen[TestCase{ExampleNum: 1, Extensions: "table"}], "TestCase should be usable as a map key")
+	require.False(t, testCase == TestCase{ExampleNum: 1, Extensions: "table strikethrough"})
+}
+
 // ----------------------------------------------------------------------------
 //  getNamesFile()
 // ----------------------------------------------------------------------------
@@ -72,30 +87,22 @@ func Test_isValidFormatVer(t *testing.T) {
 //  ListVersion()
 // ----------------------------------------------------------------------------
 
-//nolint:paralleltest // do not parallelize due to dependency on other tests
-func TestListVersion_cache(t *testing.T) {
-	oldVersionList := versionList
-
-	defer func() {
-		versionList = oldVersionList
-	}()
-
-	// Clear cache
-	versionList = nil
+func TestListVersion_copy(t *testing.T) {
+	t.Parallel()
 
 	listFirst, err := ListVersion()
 	require.NoError(t, err)
 
-	// Modify the cache to test if the second call uses it
-	versionList = []string{"cached_version"}
+	expect := slices.Clone(listFirst)
+
+	// Modify the returned list
+	slices.Reverse(listFirst)
+	listFirst[0] = "modified"
 
 	listSecond, err := ListVersion()
 	require.NoError(t, err)
 
-	require.Equal(t, []string{"cached_version"}, listSecond,
-		"second call should return cached version list")
-	require.NotEqual(t, listFirst, listSecond,
-		"first and second call results should differ due to caching")
+	require.Equal(t, expect, listSecond, "modifying the returned list should not affect the cache")
 }
 
 func TestListVersion_contains_all(t *testing.T) {
@@ -130,6 +137,8 @@ func TestListVersion_contains_all(t *testing.T) {
 
 //nolint:paralleltest // do not parallelize due to dependency on other tests
 func TestListVersion_fail_to_unmarshal(t *testing.T) {
+	resetCaches(t)
+
 	// Backup and defer restore the file name
 	oldJSONUnmarshal := jsonUnmarshal
 
@@ -151,6 +160,8 @@ func TestListVersion_fail_to_unmarshal(t *testing.T) {
 
 //nolint:paralleltest // do not parallelize due to dependency on other tests
 func TestListVersion_non_existing_dir(t *testing.T) {
+	resetCaches(t)
+
 	// Backup and defer restore the file name
 	oldNameFileSpecList := nameFileSpecList
 
@@ -198,6 +209,8 @@ func TestSpecCheck_golden(t *testing.T) {
 
 //nolint:paralleltest // do not parallelize due to dependency on other tests
 func TestSpecCheck_fail_to_get_spec_file(t *testing.T) {
+	resetCaches(t)
+
 	// Backup and defer restore the file name
 	oldNameFileSpecList := nameFileSpecList
 
@@ -247,6 +260,8 @@ func TestSpecCheck_function_error(t *testing.T) {
 
 //nolint:paralleltest // do not parallelize due to dependency on other tests
 func TestSpecCheck_spec_version_error(t *testing.T) {
+	resetCaches(t)
+
 	// Backup and defer restore functions
 	oldJSONUnmarshal := jsonUnmarshal
 
@@ -610,8 +625,6 @@ func getGoldenParser(t *testing.T, reqVer string) func(string) (string, error) {
 }
 
 // prepareTestCasesMap loads test cases and creates a map for lookup.
-//
-//nolint:unparam // it always receives "spec_v0.13.json" for now
 func prepareTestCasesMap(tb testing.TB, specFile string) ([]TestCase, map[string]string) {
 	tb.Helper()
 
@@ -0,0 +1,895 @@
+package mdspec
+
+import (
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WithNormalizedHTML compares the expected and actual HTML after normalizing
+// both with NormalizeHTML instead of comparing them as is. This is the same as
+// running "spec_tests.py" of the CommonMark spec with the "--normalize" flag.
+// It is a shorthand for WithComparator(NormalizedComparator()).
+//
+// The Expected and Actual fields of *MismatchError and the Actual field of
+// Result still hold the HTML before normalization.
+//
+// Usage:
+//
+//	err := mdspec.SpecCheck("v0.30", myFunc, mdspec.WithNormalizedHTML())
+func WithNormalizedHTML() Option {
+	return func(conf *config) {
+		conf.comparator = NormalizedComparator()
+	}
+}
+
+// NormalizeHTML returns the normalized form of the given HTML. It is a port of
+// "normalize.py" of the CommonMark spec and reproduces its output, including its
+// quirks, so that the results match the reference test runner.
+//
+// The normalization:
+//   - removes whitespace around block-level tags and collapses other whitespace
+//     into a single space, except inside "<pre>".
+//   - lowercases tag and attribute names and sorts the attributes.
+//   - quotes attribute values with double quotes and escapes them uniformly.
+//   - resolves entity and numeric character references except for "<", ">",
+//     "&" and `"`.
+//   - drops the slash of self-closing tags, e.g. "<br />" becomes "<br>".
+//
+// Example:
+//
+//	mdspec.NormalizeHTML("<p>a<br />\nb &quot;c&#x22;</p>\n")
+//	// Output: <p>a<br>b &quot;c&quot;</p>
+func NormalizeHTML(html string) string {
+	normalizer := &htmlNormalizer{last: "starttag"}
+
+	for _, chunk := range htmlChunkRe.FindAllString(html, -1) {
+		if strings.HasPrefix(chunk, "<![CDATA") {
+			normalizer.output = append(normalizer.output, chunk...)
+
+			continue
+		}
+
+		normalizer.feed(chunk)
+	}
+
+	normalizer.close()
+
+	return string(normalizer.output)
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// pySpaces is the regexp character class of the whitespace characters in Python.
+// The normalizer follows Python's "str.isspace" and "\s" of the "re" module, which
+// are wider than "\s" of Go.
+const pySpaces = `[\t\n\v\f\r \x{1c}-\x{1f}\x{85}\x{a0}\x{1680}\x{2000}-\x{200a}` +
+	`\x{2028}\x{2029}\x{202f}\x{205f}\x{3000}]`
+
+var (
+	// htmlChunkRe splits the HTML into chunks to feed to the parser. CDATA
+	// sections on a single line are passed through as is.
+	htmlChunkRe = regexp.MustCompile(`<!\[CDATA\[.*?\]\]>|<[^>]*>|[^<]+`)
+	// whitespaceRe matches a run of whitespace to collapse.
+	whitespaceRe = regexp.MustCompile(pySpaces + `+`)
+	// charrefRe matches a numeric character reference and its terminator.
+	charrefRe = regexp.MustCompile(`^&#([0-9]+|[xX][0-9a-fA-F]+)([^0-9a-fA-F])`)
+	// entityrefRe matches a named character reference and its terminator.
+	entityrefRe = regexp.MustCompile(`^&([a-zA-Z][-.a-zA-Z0-9]*)([^a-zA-Z0-9])`)
+	// incompleteRe matches the beginning of a reference that may be incomplete.
+	incompleteRe = regexp.MustCompile(`^&[a-zA-Z#]`)
+	// endTagRe matches a well-formed end tag.
+	endTagRe = regexp.MustCompile(`^</` + pySpaces + `*([a-zA-Z][-.a-zA-Z0-9:_]*)` + pySpaces + `*>`)
+	// commentCloseRe matches the end of a comment.
+	commentCloseRe = regexp.MustCompile(`--` + pySpaces + `*>`)
+	// markedSectionCloseRe and msMarkedSectionCloseRe match the end of a marked
+	// section such as "<![CDATA[...]]>" and "<![if ...]>" respectively.
+	markedSectionCloseRe   = regexp.MustCompile(`]` + pySpaces + `*]` + pySpaces + `*>`)
+	msMarkedSectionCloseRe = regexp.MustCompile(`]` + pySpaces + `*>`)
+	// declNameRe matches the name of a marked section.
+	declNameRe = regexp.MustCompile(`^[a-zA-Z][-_.a-zA-Z0-9]*` + pySpaces + `*`)
+)
+
+// blockTags are the tags around which whitespace is removed.
+var blockTags = map[string]bool{
+	"article": true, "header": true, "aside": true, "hgroup": true, "blockquote": true,
+	"hr": true, "iframe": true, "body": true, "li": true, "map": true, "button": true,
+	"object": true, "canvas": true, "ol": true, "caption": true, "output": true,
+	"col": true, "p": true, "colgroup": true, "pre": true, "dd": true, "progress": true,
+	"div": true, "section": true, "dl": true, "table": true, "td": true, "dt": true,
+	"tbody": true, "embed": true, "textarea": true, "fieldset": true, "tfoot": true,
+	"figcaption": true, "th": true, "figure": true, "thead": true, "footer": true,
+	"tr": true, "form": true, "ul": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "video": true, "script": true, "style": true,
+}
+
+// htmlAttr is an attribute of a start tag. A nil value means an attribute
+// without a value, such as "disabled".
+type htmlAttr struct {
+	value *string
+	name  string
+}
+
+// htmlNormalizer is a port of the "html.parser.HTMLParser" of Python with the
+// handlers of "normalize.py". The parser part follows Python 3.11 and keeps the
+// unparsed data in rawdata until more data is fed, as the original does.
+type htmlNormalizer struct {
+	rawdata string
+	// cdataElem is the name of the element whose content is raw text, i.e.
+	// "script" or "style", while the parser is inside it.
+	cdataElem string
+	// last is the kind of the last handled token and lastTag is the name of the
+	// last handled tag.
+	last    string
+	lastTag string
+	output  []byte
+	inPre   bool
+}
+
+// feed parses as much of the given data as possible.
+func (n *htmlNormalizer) feed(data string) {
+	n.rawdata += data
+	n.goahead(false)
+}
+
+// close parses all the remaining data.
+func (n *htmlNormalizer) close() {
+	n.goahead(true)
+}
+
+// goahead parses the buffered data. If end is false, it stops at a construct
+// that may be completed by the following data.
+//
+//nolint:cyclop,funlen,gocognit // keep the structure of the original for comparison
+func (n *htmlNormalizer) goahead(end bool) {
+	rawdata := n.rawdata
+	index, length := 0, len(rawdata)
+
+loop:
+	for index < length {
+		var next int
+
+		if n.cdataElem != "" {
+			loc := cdataEndRe(n.cdataElem).FindStringIndex(rawdata[index:])
+			if loc == nil {
+				break
+			}
+
+			next = index + loc[0]
+		} else {
+			next = strings.IndexAny(rawdata[index:], "<&")
+			if next < 0 {
+				next = length
+			} else {
+				next += index
+			}
+		}
+
+		if index < next {
+			n.handleData(rawdata[index:next])
+		}
+
+		index = next
+		if index == length {
+			break
+		}
+
+		rest := rawdata[index:]
+
+		switch {
+		case rest[0] == '<':
+			var pos int
+
+			switch {
+			case len(rest) > 1 && isASCIILetter(rest[1]):
+				pos = n.parseStartTag(index)
+			case strings.HasPrefix(rest, "</"):
+				pos = n.parseEndTag(index)
+			case strings.HasPrefix(rest, "<!--"):
+				pos = n.parseComment(index)
+			case strings.HasPrefix(rest, "<?"):
+				pos = n.parsePI(index)
+			case strings.HasPrefix(rest, "<!"):
+				pos = n.parseHTMLDeclaration(index)
+			case len(rest) > 1:
+				n.handleData("<")
+
+				pos = index + 1
+			default:
+				break loop
+			}
+
+			if pos < 0 {
+				if !end {
+					break loop
+				}
+
+				pos = endOfMalformed(rawdata, index)
+
+				n.handleData(rawdata[index:pos])
+			}
+
+			index = pos
+		case strings.HasPrefix(rest, "&#"):
+			if match := charrefRe.FindStringSubmatch(rest); match != nil {
+				n.handleCharref(match[1])
+
+				index += len(match[0])
+				if match[2] != ";" {
+					index -= len(match[2])
+				}
+
+				continue
+			}
+
+			if strings.Contains(rest, ";") { // bail by consuming "&#"
+				n.handleData("&#")
+
+				index += 2
+			}
+
+			break loop
+		default: // rest[0] == '&'
+			if match := entityrefRe.FindStringSubmatch(rest); match != nil {
+				n.handleEntityref(match[1])
+
+				index += len(match[0])
+				if match[2] != ";" {
+					index -= len(match[2])
+				}
+
+				continue
+			}
+
+			if match := incompleteRe.FindString(rest); match != "" {
+				if end && match == rest {
+					index++
+				}
+
+				break loop
+			}
+
+			if len(rest) == 1 {
+				break loop
+			}
+
+			n.handleData("&")
+
+			index++
+		}
+	}
+
+	if end && index < length && n.cdataElem == "" {
+		n.handleData(rawdata[index:])
+
+		index = length
+	}
+
+	n.rawdata = rawdata[index:]
+}
+
+// parseStartTag parses the start tag at index and returns the position after
+// it, or -1 if it is incomplete.
+func (n *htmlNormalizer) parseStartTag(index int) int {
+	endPos := n.checkForWholeStartTag(index)
+	if endPos < 0 {
+		return endPos
+	}
+
+	rawdata := n.rawdata
+	pos := scanTagName(rawdata, index+1)
+	tag := strings.ToLower(rawdata[index+1 : pos])
+	pos = skipSpaceOrSlash(rawdata, pos)
+
+	var attrs []htmlAttr
+
+	for pos < endPos {
+		attr, next, ok := matchAttr(rawdata, pos)
+		if !ok {
+			break
+		}
+
+		if attr.value != nil {
+			value := *attr.value
+			if len(value) > 1 && (value[0] == '\'' || value[0] == '"') && value[0] == value[len(value)-1] {
+				value = value[1 : len(value)-1]
+			}
+
+			if value != "" {
+				value = html.UnescapeString(value)
+			}
+
+			attr.value = &value
+		}
+
+		attr.name = strings.ToLower(attr.name)
+		attrs = append(attrs, attr)
+		pos = next
+	}
+
+	rest := strings.TrimFunc(rawdata[pos:endPos], isPySpace)
+	if rest != ">" && rest != "/>" {
+		n.handleData(rawdata[index:endPos])
+
+		return endPos
+	}
+
+	if strings.HasSuffix(rest, "/>") {
+		n.handleStartEndTag(tag, attrs)
+	} else {
+		n.handleStartTag(tag, attrs)
+
+		if tag == "script" || tag == "style" {
+			n.cdataElem = tag
+		}
+	}
+
+	return endPos
+}
+
+// checkForWholeStartTag returns the position after the start tag at index, or
+// -1 if it is incomplete.
+func (n *htmlNormalizer) checkForWholeStartTag(index int) int {
+	rawdata := n.rawdata
+	pos := scanTagName(rawdata, index+1)
+
+	for pos < len(rawdata) {
+		if rawdata[pos] == '/' {
+			pos++
+
+			continue
+		}
+
+		size := pySpaceSize(rawdata, pos)
+		if size == 0 {
+			break
+		}
+
+		pos += size
+	}
+
+	for {
+		_, next, ok := matchAttr(rawdata, pos)
+		if !ok {
+			break
+		}
+
+		pos = next
+	}
+
+	pos = skipSpace(rawdata, pos)
+
+	rest := rawdata[pos:]
+
+	switch {
+	case strings.HasPrefix(rest, ">"):
+		return pos + 1
+	case strings.HasPrefix(rest, "/>"):
+		return pos + 2
+	case rest == "", rest[0] == '/', rest[0] == '=', isASCIILetter(rest[0]):
+		return -1
+	default:
+		return pos
+	}
+}
+
+// parseEndTag parses the end tag at index and returns the position after it,
+// or -1 if it is incomplete.
+func (n *htmlNormalizer) parseEndTag(index int) int {
+	rawdata := n.rawdata
+
+	gtPos := strings.IndexByte(rawdata[index+1:], '>')
+	if gtPos < 0 {
+		return -1
+	}
+
+	gtPos += index + 1 + 1
+
+	match := endTagRe.FindStringSubmatch(rawdata[index:gtPos])
+	if match == nil {
+		if n.cdataElem != "" {
+			n.handleData(rawdata[index:gtPos])
+
+			return gtPos
+		}
+
+		if index+2 >= len(rawdata) || !isASCIILetter(rawdata[index+2]) {
+			if strings.HasPrefix(rawdata[index:], "</>") {
+				return index + 3
+			}
+
+			return n.parseBogusComment(index)
+		}
+
+		// Ignore everything between the name and the ">"
+		nameEnd := scanTagName(rawdata, index+2)
+		n.handleEndTag(strings.ToLower(rawdata[index+2 : nameEnd]))
+
+		return gtPos
+	}
+
+	// In raw text mode, goahead stops only at the end tag of the element.
+	n.handleEndTag(strings.ToLower(match[1]))
+	n.cdataElem = ""
+
+	return gtPos
+}
+
+// parseComment parses the comment at index and returns the position after it,
+// or -1 if it is incomplete.
+func (n *htmlNormalizer) parseComment(index int) int {
+	loc := commentCloseRe.FindStringIndex(n.rawdata[index+4:])
+	if loc == nil {
+		return -1
+	}
+
+	n.handleComment(n.rawdata[index+4 : index+4+loc[0]])
+
+	return index + 4 + loc[1]
+}
+
+// parseBogusComment parses a malformed construct starting with "<!" or "</" as
+// a comment and returns the position after it, or -1 if it is incomplete.
+func (n *htmlNormalizer) parseBogusComment(index int) int {
+	pos := strings.IndexByte(n.rawdata[index+2:], '>')
+	if pos < 0 {
+		return -1
+	}
+
+	n.handleComment(n.rawdata[index+2 : index+2+pos])
+
+	return index + 2 + pos + 1
+}
+
+// parsePI parses the processing instruction at index and returns the position
+// after it, or -1 if it is incomplete.
+func (n *htmlNormalizer) parsePI(index int) int {
+	pos := strings.IndexByte(n.rawdata[index+2:], '>')
+	if pos < 0 {
+		return -1
+	}
+
+	n.handlePI(n.rawdata[index+2 : index+2+pos])
+
+	return index + 2 + pos + 1
+}
+
+// parseHTMLDeclaration parses the declaration at index and returns the position
+// after it, or -1 if it is incomplete.
+func (n *htmlNormalizer) parseHTMLDeclaration(index int) int {
+	rawdata := n.rawdata
+
+	switch {
+	case strings.HasPrefix(rawdata[index:], "<!["):
+		return n.parseMarkedSection(index)
+	case len(rawdata) >= index+9 && strings.EqualFold(rawdata[index:index+9], "<!doctype"):
+		pos := strings.IndexByte(rawdata[index+9:], '>')
+		if pos < 0 {
+			return -1
+		}
+
+		n.handleDecl(rawdata[index+2 : index+9+pos])
+
+		return index + 9 + pos + 1
+	default:
+		return n.parseBogusComment(index)
+	}
+}
+
+// parseMarkedSection parses the marked section at index, such as a CDATA section
+// spanning lines, and returns the position after it, or -1 if it is incomplete.
+//
+// Python raises an error on an unknown section name. Such a section is parsed
+// as a bogus comment here instead.
+func (n *htmlNormalizer) parseMarkedSection(index int) int {
+	rawdata := n.rawdata
+	if index+3 == len(rawdata) {
+		return -1
+	}
+
+	name := declNameRe.FindString(rawdata[index+3:])
+	if name == "" {
+		return n.parseBogusComment(index)
+	}
+
+	if index+3+len(name) == len(rawdata) {
+		return -1
+	}
+
+	var closeRe *regexp.Regexp
+
+	switch strings.ToLower(strings.TrimRightFunc(name, isPySpace)) {
+	case "temp", "cdata", "ignore", "include", "rcdata":
+		closeRe = markedSectionCloseRe
+	case "if", "else", "endif":
+		closeRe = msMarkedSectionCloseRe
+	default:
+		return n.parseBogusComment(index)
+	}
+
+	loc := closeRe.FindStringIndex(rawdata[index+3:])
+	if loc == nil {
+		return -1
+	}
+
+	n.handleUnknownDecl(rawdata[index+3 : index+3+loc[0]])
+
+	return index + 3 + loc[1]
+}
+
+// handleData handles the text between tags.
+func (n *htmlNormalizer) handleData(data string) {
+	afterTag := n.last == "endtag" || n.last == "starttag"
+	afterBlockTag := afterTag && blockTags[n.lastTag]
+
+	if afterTag && n.lastTag == "br" {
+		data = strings.TrimLeft(data, "\n")
+	}
+
+	if !n.inPre {
+		data = whitespaceRe.ReplaceAllLiteralString(data, " ")
+	}
+
+	if afterBlockTag && !n.inPre {
+		switch n.last {
+		case "starttag":
+			data = strings.TrimLeftFunc(data, isPySpace)
+		case "endtag":
+			data = strings.TrimFunc(data, isPySpace)
+		}
+	}
+
+	n.output = append(n.output, data...)
+	n.last = "data"
+}
+
+// handleStartTag handles a start tag.
+func (n *htmlNormalizer) handleStartTag(tag string, attrs []htmlAttr) {
+	if tag == "pre" {
+		n.inPre = true
+	}
+
+	if blockTags[tag] {
+		n.rstripOutput()
+	}
+
+	n.output = append(n.output, "<"+tag...)
+
+	slices.SortStableFunc(attrs, compareAttrs)
+
+	for _, attr := range attrs {
+		n.output = append(n.output, " "+attr.name...)
+
+		if attr.value != nil {
+			n.output = append(n.output, `="`+escapeAttr(*attr.value)+`"`...)
+		}
+	}
+
+	n.output = append(n.output, '>')
+	n.lastTag = tag
+	n.last = "starttag"
+}
+
+// handleStartEndTag handles a self-closing tag as a start tag without the end
+// tag.
+func (n *htmlNormalizer) handleStartEndTag(tag string, attrs []htmlAttr) {
+	n.handleStartTag(tag, attrs)
+	n.lastTag = tag
+	n.last = "endtag"
+}
+
+// handleEndTag handles an end tag.
+func (n *htmlNormalizer) handleEndTag(tag string) {
+	if tag == "pre" {
+		n.inPre = false
+	} else if blockTags[tag] {
+		n.rstripOutput()
+	}
+
+	n.output = append(n.output, "</"+tag+">"...)
+	n.lastTag = tag
+	n.last = "endtag"
+}
+
+// handleComment handles a comment.
+func (n *htmlNormalizer) handleComment(data string) {
+	n.output = append(n.output, "<!--"+data+"-->"...)
+	n.last = "comment"
+}
+
+// handleDecl handles a doctype declaration.
+func (n *htmlNormalizer) handleDecl(data string) {
+	n.output = append(n.output, "<!"+data+">"...)
+	n.last = "decl"
+}
+
+// handleUnknownDecl handles a marked section.
+func (n *htmlNormalizer) handleUnknownDecl(data string) {
+	n.output = append(n.output, "<!"+data+">"...)
+	n.last = "decl"
+}
+
+// handlePI handles a processing instruction.
+func (n *htmlNormalizer) handlePI(data string) {
+	n.output = append(n.output, "<?"+data+">"...)
+	n.last = "pi"
+}
+
+// handleEntityref handles a named character reference.
+func (n *htmlNormalizer) handleEntityref(name string) {
+	codepoint, ok := htmlEntityCodepoints[name]
+	n.outputChar(codepoint, ok, "&"+name+";")
+	n.last = "ref"
+}
+
+// handleCharref handles a numeric character reference. The name is the part
+// after "&#", e.g. "x22".
+func (n *htmlNormalizer) handleCharref(name string) {
+	var (
+		codepoint int64
+		err       error
+	)
+
+	if strings.HasPrefix(name, "x") {
+		codepoint, err = strconv.ParseInt(name[1:], 16, 32)
+	} else {
+		codepoint, err = strconv.ParseInt(name, 10, 32)
+	}
+
+	ok := err == nil && codepoint <= unicode.MaxRune
+	n.outputChar(rune(codepoint), ok, "&"+name+";")
+	n.last = "ref"
+}
+
+// outputChar writes the character escaping the HTML special characters. If ok
+// is false, it writes the fallback instead.
+func (n *htmlNormalizer) outputChar(char rune, ok bool, fallback string) {
+	switch {
+	case !ok:
+		n.output = append(n.output, fallback...)
+	case char == '<':
+		n.output = append(n.output, "&lt;"...)
+	case char == '>':
+		n.output = append(n.output, "&gt;"...)
+	case char == '&':
+		n.output = append(n.output, "&amp;"...)
+	case char == '"':
+		n.output = append(n.output, "&quot;"...)
+	default:
+		n.output = utf8.AppendRune(n.output, char)
+	}
+}
+
+// rstripOutput removes the trailing whitespace of the output.
+func (n *htmlNormalizer) rstripOutput() {
+	n.output = []byte(strings.TrimRightFunc(string(n.output), isPySpace))
+}
+
+// cdataEndRe returns the regexp that matches the end tag of the raw text
+// element, which is the only thing that ends its content.
+func cdataEndRe(elem string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)</` + pySpaces + `*` + elem + pySpaces + `*>`)
+}
+
+// compareAttrs orders the attributes by name and then by value. An attribute
+// without a value comes first.
+func compareAttrs(a, b htmlAttr) int {
+	if cmp := strings.Compare(a.name, b.name); cmp != 0 {
+		return cmp
+	}
+
+	switch {
+	case a.value == nil && b.value == nil:
+		return 0
+	case a.value == nil:
+		return -1
+	case b.value == nil:
+		return 1
+	default:
+		return strings.Compare(*a.value, *b.value)
+	}
+}
+
+// endOfMalformed returns the end of the malformed construct at index that is
+// handled as text at the end of the input. It ends after the next ">", before
+// the next "<", or after the first character, in that order of preference.
+func endOfMalformed(rawdata string, index int) int {
+	if pos := strings.IndexByte(rawdata[index+1:], '>'); pos >= 0 {
+		return index + 1 + pos + 1
+	}
+
+	if pos := strings.IndexByte(rawdata[index+1:], '<'); pos >= 0 {
+		return index + 1 + pos
+	}
+
+	return index + 1
+}
+
+// escapeAttr escapes the attribute value like "html.escape(value, quote=True)"
+// of Python.
+func escapeAttr(value string) string {
+	return strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		`"`, "&quot;",
+		"'", "&#x27;",
+	).Replace(value)
+}
+
+// isASCIILetter returns true if the byte is an ASCII letter.
+func isASCIILetter(char byte) bool {
+	return ('a' <= char && char <= 'z') || ('A' <= char && char <= 'Z')
+}
+
+// isPySpace returns true if the rune is whitespace in Python.
+func isPySpace(char rune) bool {
+	return unicode.IsSpace(char) || (0x1c <= char && char <= 0x1f)
+}
+
+// matchAttr matches an attribute at pos and returns it with the position after
+// it and the following whitespace and slashes. The value is as written, with
+// the quotes if any.
+func matchAttr(rawdata string, pos int) (htmlAttr, int, bool) {
+	if pos == 0 || pos >= len(rawdata) {
+		return htmlAttr{}, pos, false
+	}
+
+	// The attribute must follow a quote, whitespace or a slash.
+	prev, _ := utf8.DecodeLastRuneInString(rawdata[:pos])
+	if prev != '\'' && prev != '"' && prev != '/' && !isPySpace(prev) {
+		return htmlAttr{}, pos, false
+	}
+
+	first, size := utf8.DecodeRuneInString(rawdata[pos:])
+	if first == '/' || first == '>' || isPySpace(first) {
+		return htmlAttr{}, pos, false
+	}
+
+	nameEnd := pos + size
+	for nameEnd < len(rawdata) {
+		char, size := utf8.DecodeRuneInString(rawdata[nameEnd:])
+		if char == '/' || char == '=' || char == '>' || isPySpace(char) {
+			break
+		}
+
+		nameEnd += size
+	}
+
+	attr := htmlAttr{name: rawdata[pos:nameEnd]}
+	end := nameEnd
+
+	if value, valueEnd, ok := matchAttrValue(rawdata, nameEnd); ok {
+		attr.value = &value
+		end = valueEnd
+	}
+
+	return attr, skipSpaceOrSlash(rawdata, end), true
+}
+
+// matchAttrValue matches the "=value" part of an attribute at pos. It emulates
+// the backtracking of the regexp `\s*=+\s*('[^']*'|"[^"]*"|(?!['"])[^>\s]*)\s*`
+// of Python.
+func matchAttrValue(rawdata string, pos int) (string, int, bool) {
+	pos = skipSpace(rawdata, pos)
+
+	numEquals := len(rawdata[pos:]) - len(strings.TrimLeft(rawdata[pos:], "="))
+
+	for equals := numEquals; equals > 0; equals-- {
+		start := pos + equals
+		spaces := spacePositions(rawdata, start)
+
+		for index := len(spaces) - 1; index >= 0; index-- {
+			valueStart := spaces[index]
+			if valueEnd, ok := matchValue(rawdata, valueStart); ok {
+				return rawdata[valueStart:valueEnd], skipSpace(rawdata, valueEnd), true
+			}
+		}
+	}
+
+	return "", pos, false
+}
+
+// matchValue matches a quoted or unquoted attribute value at pos and returns
+// the position after it.
+func matchValue(rawdata string, pos int) (int, bool) {
+	if pos < len(rawdata) && (rawdata[pos] == '\'' || rawdata[pos] == '"') {
+		closing := strings.IndexByte(rawdata[pos+1:], rawdata[pos])
+		if closing < 0 {
+			return pos, false
+		}
+
+		return pos + 1 + closing + 1, true
+	}
+
+	end := pos
+	for end < len(rawdata) {
+		char, size := utf8.DecodeRuneInString(rawdata[end:])
+		if char == '>' || isPySpace(char) {
+			break
+		}
+
+		end += size
+	}
+
+	return end, true
+}
+
+// pySpaceSize returns the byte size of the whitespace rune at pos, or 0 if it
+// is not whitespace.
+func pySpaceSize(rawdata string, pos int) int {
+	if pos >= len(rawdata) {
+		return 0
+	}
+
+	char, size := utf8.DecodeRuneInString(rawdata[pos:])
+	if !isPySpace(char) {
+		return 0
+	}
+
+	return size
+}
+
+// scanTagName returns the position after the tag name starting at pos.
+func scanTagName(rawdata string, pos int) int {
+	end := strings.IndexAny(rawdata[pos:], "\t\n\r\f />\x00")
+	if end < 0 {
+		return len(rawdata)
+	}
+
+	return pos + end
+}
+
+// skipSpace returns the position after the whitespace at pos.
+func skipSpace(rawdata string, pos int) int {
+	for {
+		size := pySpaceSize(rawdata, pos)
+		if size == 0 {
+			return pos
+		}
+
+		pos += size
+	}
+}
+
+// skipSpaceOrSlash returns the position after the whitespace and slashes at pos.
+// A slash followed by ">" is not skipped.
+func skipSpaceOrSlash(rawdata string, pos int) int {
+	for pos < len(rawdata) {
+		if size := pySpaceSize(rawdata, pos); size > 0 {
+			pos += size
+
+			continue
+		}
+
+		if rawdata[pos] != '/' || strings.HasPrefix(rawdata[pos:], "/>") {
+			break
+		}
+
+		pos++
+	}
+
+	return pos
+}
+
+// spacePositions returns the positions from pos to the end of the whitespace
+// at pos, in ascending order.
+func spacePositions(rawdata string, pos int) []int {
+	positions := []int{pos}
+
+	for {
+		size := pySpaceSize(rawdata, pos)
+		if size == 0 {
+			return positions
+		}
+
+		pos += size
+		positions = append(positions, pos)
+	}
+}
@@ -0,0 +1,205 @@
+package mdspec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  NormalizeHTML()
+// ----------------------------------------------------------------------------
+
+// The expected values are the output of "normalize.py" of the CommonMark spec
+// run with Python 3.11.
+func TestNormalizeHTML(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  string
+		expect string
+	}{
+		// Whitespace around block tags and in text
+		{input: "<p>foo</p>\n", expect: "<p>foo</p>"},
+		{input: "  <ul>\n  <li>  foo  </li>\n</ul>  ", expect: "<ul><li>foo</li></ul>"},
+		{input: "<div>\n<p>x</p>\n</div>\n", expect: "<div><p>x</p></div>"},
+		{input: "<p>a  \t b\nc</p>", expect: "<p>a b c</p>"},
+		{input: "<pre>  a\n\n b  </pre>\n", expect: "<pre>  a\n\n b  </pre>"},
+		{input: "<input disabled>\n<br>foo", expect: "<input disabled> <br>foo"},
+		{input: "a　　b", expect: "a b"},
+		// Self-closing tags
+		{input: "<p>a<br/>\nb</p>", expect: "<p>a<br>b</p>"},
+		{input: "<p>x</p >\n<br />\n\nfoo", expect: "<p>x</p><br>foo"},
+		{input: "<hr />\n", expect: "<hr>"},
+		// Attributes
+		{input: `<img src="a.png" alt="&lt;x&gt;" />`, expect: `<img alt="&lt;x&gt;" src="a.png">`},
+		{input: `<P CLASS='x' id=y>x</P>`, expect: `<p class="x" id="y">x</p>`},
+		{input: `<a href="/%C3%A4" title='a"b'>x</a>`, expect: `<a href="/%C3%A4" title="a&quot;b">x</a>`},
+		{input: `<x y='a&amp;b&#39;c'>`, expect: `<x y="a&amp;b&#x27;c">`},
+		{input: `<a href="x y" HREF=z>`, expect: `<a href="x y" href="z">`},
+		{input: `<a b== 'c' d = "e" f= 'g>x</a>`, expect: `<a 'g b="c" d="e" f="">x</a>`},
+		{input: "<img alt>", expect: "<img alt>"},
+		// References
+		{input: "a &copy; &#169; &#xA9; &#0; &#xZZ; b", expect: "a © © © \x00 &#xZZ; b"},
+		{input: "&quot;&#39;&#x27;&unknown;&amp;", expect: "&quot;''&unknown;&amp;"},
+		{input: "&lt;&gt;&amp;&quot;&apos;", expect: "&lt;&gt;&amp;&quot;&apos;"},
+		{input: "<p>&#123", expect: "<p>&#123"},
+		{input: "<p>&a", expect: "<p>a"},
+		// Comments, declarations and others
+		{
+			input:  "<!-- c -->\n<?php x ?>\n<!DOCTYPE html>\n<![CDATA[ a < b ]]>",
+			expect: "<!-- c --> <?php x ?> <!DOCTYPE html> <![CDATA[ a < b ]]>",
+		},
+		{input: "<![CDATA[\nfoo\n]]>", expect: "<![CDATA[\nfoo\n]]>"},
+		{input: "</ a>", expect: "</a>"},
+		{input: "<!bogus>", expect: "<!--bogus-->"},
+		{input: "</>", expect: ""},
+		{input: "<?x", expect: "?x"},
+		{input: "<![if x]>y", expect: "<!if x>y"},
+		{input: "<![endif]>", expect: "<!endif>"},
+		// Incomplete or malformed constructs
+		{input: "<![", expect: "!["},
+		{input: "<![if", expect: "![if"},
+		{input: "<![CDATA[\nfoo", expect: "![CDATA[ foo"},
+		{input: "<!--x", expect: "!--x"},
+		{input: "<!-", expect: "!-"},
+		{input: "<!doctype", expect: "!doctype"},
+		{input: "<!doctype x", expect: "!doctype x"},
+		{input: "</", expect: "/"},
+		{input: "</x", expect: "/x"},
+		{input: "</a b>", expect: "</a>"},
+		{input: "</a\n>", expect: "</a>"},
+		{input: "<div", expect: "div"},
+		{input: "<a b='c", expect: "a b='c"},
+		{input: "<a b", expect: "a b"},
+		{input: "<a <", expect: "a "},
+		{input: "< a", expect: " a"},
+		{input: "x<", expect: "x"},
+		{input: "<a =x>", expect: "<a =x>"},
+		{input: "<a\x00>", expect: "<a\x00>"},
+		{input: "<a/b/c>", expect: "<a b c>"},
+		{input: "<a b='c'd>", expect: `<a b="c" d>`},
+		{input: "<a b=c/>x", expect: `<a b="c/">x`},
+		{input: `<a b="c"'>`, expect: `<a ' b="c">`},
+		{input: "&", expect: "&"},
+		{input: "x&y&", expect: "x&y;&"},
+		{input: "&#x110000;", expect: "&x110000;"},
+		{input: "<p>x</p>&#x;", expect: "<p>x</p>&#x;"},
+		{input: "<p>\u2003x", expect: "<p>x"},
+		// Python raises an error on these. They are handled as bogus comments
+		// and valueless attributes come first here.
+		{input: "<![foo]>", expect: "<!--[foo]-->"},
+		{input: "<![ x>", expect: "<!--[ x-->"},
+		{input: "<b c=1 c d c>", expect: `<b c c c="1" d>`},
+		{input: "<b c c=1>", expect: `<b c c="1">`},
+		// Raw text elements
+		{input: "<script></p></script>", expect: "<script></p></script>"},
+		{input: "<style>a</b></style>", expect: "<style>a</b></style>"},
+		{
+			input:  "<script>\n  a < b && c\n</SCRIPT >\n<p>z</p>",
+			expect: "<script>a < b && c</script><p>z</p>",
+		},
+		{input: "<style>x", expect: "<style>"},
+	} {
+		assert.Equal(t, test.expect, NormalizeHTML(test.input), "input: %q", test.input)
+	}
+}
+
+func TestNormalizeHTML_spec_examples(t *testing.T) {
+	t.Parallel()
+
+	testCases, _ := prepareTestCasesMap(t, "spec_v0.31.2.json")
+
+	for _, testCase := range testCases {
+		normalized := NormalizeHTML(testCase.HTML)
+
+		require.Equal(t, normalized, NormalizeHTML(normalized),
+			"normalization should be idempotent. example %d", testCase.ExampleNum)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  WithNormalizedHTML()
+// ----------------------------------------------------------------------------
+
+func TestWithNormalizedHTML(t *testing.T) {
+	t.Parallel()
+
+	testCases, expectedResults := prepareTestCasesMap(t, "spec_v0.31.2.json")
+
+	variants := map[string]string{}
+
+	for _, testCase := range testCases {
+		variants[testCase.Markdown] = NormalizeHTML(expectedResults[testCase.Markdown])
+	}
+
+	// Function that returns the expected HTML in another but equivalent form
+	myFunc := func(markdown string) (string, error) {
+		return variants[markdown], nil
+	}
+
+	require.Error(t, SpecCheck("v0.31.2", myFunc), "strict comparison should fail")
+	require.NoError(t, SpecCheck("v0.31.2", myFunc, WithNormalizedHTML()))
+	require.NoError(t, SpecCheckWithConcurrency("v0.31.2", myFunc, -1, WithNormalizedHTML()))
+
+	report, err := RunSpec("v0.31.2", myFunc, WithNormalizedHTML())
+	require.NoError(t, err)
+	assert.Equal(t, report.Total, report.Passed)
+
+	// Differences that matter still fail
+	err = SpecCheck("v0.31.2", func(string) (string, error) {
+		return "<p>bad HTML</p>", nil
+	}, WithNormalizedHTML(), WithExamples(1))
+
+	var mismatchErr *MismatchError
+
+	require.ErrorAs(t, err, &mismatchErr)
+	assert.Equal(t, "<p>bad HTML</p>", mismatchErr.Actual, "actual HTML should not be normalized")
+}
+
+// ----------------------------------------------------------------------------
+//  htmlNormalizer
+// ----------------------------------------------------------------------------
+
+// NormalizeHTML feeds the parser in chunks that end with ">", so some paths of
+// the parser are only reachable by feeding it directly. The expected values are
+// the output of the parser of "normalize.py" fed with the whole input at once.
+func Test_htmlNormalizer_feed(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  string
+		expect string
+	}{
+		{input: "a < b", expect: "a < b"},
+		{input: "x<", expect: "x<"},
+		{input: "&#123 x", expect: "{ x"},
+		{input: "<!--x>y", expect: "<!--x>y"},
+		{input: "<!--x", expect: "<!--x"},
+		{input: "<!--x <b>", expect: "<!--x <b>"},
+		{input: "<!--x <b", expect: "<!--x <b"},
+		{input: "<a", expect: "<a"},
+		{input: "<a/b", expect: "<a/b"},
+		{input: "<a b=c", expect: "<a b=c"},
+		{input: "<a b='c' =>", expect: `<a = b="c">`},
+		{input: "</a", expect: "</a"},
+		{input: "</\x00>", expect: "<!--\x00-->"},
+		{input: "<!x", expect: "<!x"},
+		{input: "<?x", expect: "<?x"},
+		{input: "<!doctype x", expect: "<!doctype x"},
+		{input: "<![", expect: "<!["},
+		{input: "<![if", expect: "<![if"},
+		{input: "<![if x", expect: "<![if x"},
+		{input: "<![CDATA[x", expect: "<![CDATA[x"},
+		{input: "<![CDATA[x]]>", expect: "<!CDATA[x>"},
+		{input: "<script>a</ſcript>b</script>c", expect: "<script>a</ſcript>b</script>c"},
+	} {
+		normalizer := &htmlNormalizer{last: "starttag"}
+
+		normalizer.feed(test.input)
+		normalizer.close()
+
+		assert.Equal(t, test.expect, string(normalizer.output), "input: %q", test.input)
+	}
+}
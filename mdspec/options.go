@@ -0,0 +1,66 @@
+package mdspec
+
+import "time"
+
+// Option configures how the spec test cases are run. Use the "With*" functions
+// to create one.
+type Option func(*config)
+
+// config holds the settings of a spec run.
+type config struct {
+	// baseline enables the regression mode if set. See WithBaseline.
+	baseline *Baseline
+	// comparator decides whether the actual HTML matches the expected one.
+	comparator Comparator
+	// filter selects the test cases to run.
+	filter filter
+	// knownFailures is the list of example numbers that are known to fail.
+	knownFailures []int
+	// renderer is the name of the checked renderer recorded in the report.
+	renderer string
+	// maxConcurrency is the maximum number of concurrent goroutines. See
+	// SpecCheckWithConcurrency for the meaning of -1 and 0.
+	maxConcurrency int
+	// suite replaces the embedded test cases if set. See WithSuite.
+	suite *Suite
+	// thresholds enables the threshold mode if not empty. See WithThresholds.
+	thresholds []Threshold
+	// timeout is the maximum duration of the function call per example. Zero
+	// means no timeout.
+	timeout time.Duration
+}
+
+// WithConcurrency sets the maximum number of concurrent goroutines for spec test
+// execution. The value follows the same rules as the "maxConcurrency" argument
+// of SpecCheckWithConcurrency.
+func WithConcurrency(maxConcurrency int) Option {
+	return func(conf *config) {
+		conf.maxConcurrency = maxConcurrency
+	}
+}
+
+// WithRenderer sets the name of the checked renderer, such as "my-parser v1.2.0",
+// to the Renderer field of the report. It identifies the renderer in the
+// archived reports. See WriteJSON.
+func WithRenderer(name string) Option {
+	return func(conf *config) {
+		conf.renderer = name
+	}
+}
+
+// newConfig returns a config with the default values overridden by the given
+// options.
+func newConfig(opts []Option) *config {
+	conf := &config{
+		comparator:     StrictComparator(),
+		maxConcurrency: defaultConcurrency,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(conf)
+		}
+	}
+
+	return conf
+}
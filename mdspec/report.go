@@ -0,0 +1,360 @@
+package mdspec
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Status represents the outcome of a single test case.
+type Status int
+
+const (
+	// StatusPass means the function returned the expected HTML.
+	StatusPass Status = iota
+	// StatusFail means the function returned an HTML different from the expected one.
+	StatusFail
+	// StatusError means the function returned an error.
+	StatusError
+	// StatusTimeout means the function did not return in time. See WithTimeout.
+	StatusTimeout
+	// StatusPanic means the function panicked.
+	StatusPanic
+)
+
+// String returns the name of the status.
+func (s Status) String() string {
+	switch s {
+	case StatusPass:
+		return "pass"
+	case StatusFail:
+		return "fail"
+	case StatusError:
+		return "error"
+	case StatusTimeout:
+		return "timeout"
+	case StatusPanic:
+		return "panic"
+	}
+
+	return "unknown"
+}
+
+// Result is the outcome of a single test case.
+//
+// In JSON, the fields of the test case are inlined, the status is its name and
+// the error is its message. See WriteJSON.
+type Result struct {
+	// Err is the error returned by the function, a *TimeoutError if the
+	// function timed out or a *PanicError if it panicked. It is nil if the
+	// status is StatusPass or StatusFail. On a report read by ReadReport, it
+	// only holds the error message.
+	Err error
+	// Actual is the HTML returned by the function.
+	Actual string
+	// Detail is the explanation of the comparator on a mismatch. See Comparator.
+	Detail string
+	// TestCase is the test case that was run.
+	TestCase TestCase
+	// Status is the outcome of the test case.
+	Status Status
+	// Duration is the time the function took to convert the markdown.
+	Duration time.Duration
+	// KnownFailure is true if the example is listed in WithKnownFailures.
+	KnownFailure bool
+}
+
+// Passed returns true if the test case passed.
+func (r Result) Passed() bool {
+	return r.Status == StatusPass
+}
+
+// Report is the result of running all the test cases of a spec version.
+type Report struct {
+	// StartedAt is the time the run started.
+	StartedAt time.Time `json:"started_at"`
+	// Version is the resolved spec version. "latest" is replaced with the
+	// actual version.
+	Version string `json:"version"`
+	// Renderer is the name of the checked renderer. See WithRenderer.
+	Renderer string `json:"renderer,omitempty"`
+	// Results holds the result of each test case in spec order.
+	Results []Result `json:"results"`
+	// Duration is the time the whole run took. It is in nanoseconds in JSON.
+	Duration time.Duration `json:"duration_ns"`
+	// Total is the number of test cases run.
+	Total int `json:"total"`
+	// Passed is the number of test cases that passed.
+	Passed int `json:"passed"`
+	// Failed is the number of test cases that returned an unexpected HTML.
+	Failed int `json:"failed"`
+	// Errored is the number of test cases in which the function returned an
+	// error.
+	Errored int `json:"errored"`
+	// TimedOut is the number of test cases in which the function did not
+	// return in time.
+	TimedOut int `json:"timed_out"`
+	// Panicked is the number of test cases in which the function panicked.
+	Panicked int `json:"panicked"`
+}
+
+// Failures returns the results of the test cases that did not pass.
+func (r *Report) Failures() []Result {
+	failures := []Result{}
+
+	for _, result := range r.Results {
+		if !result.Passed() {
+			failures = append(failures, result)
+		}
+	}
+
+	return failures
+}
+
+// PassRate returns the percentage of passed test cases. It returns 0 if no
+// test case was run.
+func (r *Report) PassRate() float64 {
+	return percentage(r.Passed, r.Total)
+}
+
+// Sections returns the pass/fail totals grouped by spec section. The sections
+// are in the same order as they appear in the specification.
+func (r *Report) Sections() []SectionResult {
+	sections := []SectionResult{}
+	indexes := map[string]int{}
+
+	for _, result := range r.Results {
+		name := result.TestCase.Section
+
+		index, ok := indexes[name]
+		if !ok {
+			index = len(sections)
+			indexes[name] = index
+
+			sections = append(sections, SectionResult{Name: name})
+		}
+
+		sections[index].count(result.Status)
+	}
+
+	return sections
+}
+
+// SectionResult is the pass/fail totals of the test cases in a spec section.
+type SectionResult struct {
+	// Name is the name of the section. E.g. "Tabs".
+	Name string
+	// Total is the number of test cases run in the section.
+	Total int
+	// Passed is the number of test cases that passed.
+	Passed int
+	// Failed is the number of test cases that returned an unexpected HTML.
+	Failed int
+	// Errored is the number of test cases in which the function returned an
+	// error.
+	Errored int
+	// TimedOut is the number of test cases in which the function did not
+	// return in time.
+	TimedOut int
+	// Panicked is the number of test cases in which the function panicked.
+	Panicked int
+}
+
+// PassRate returns the percentage of passed test cases in the section.
+func (s SectionResult) PassRate() float64 {
+	return percentage(s.Passed, s.Total)
+}
+
+// count increments the counters according to the given status.
+func (s *SectionResult) count(status Status) {
+	s.Total++
+
+	switch status {
+	case StatusPass:
+		s.Passed++
+	case StatusFail:
+		s.Failed++
+	case StatusError:
+		s.Errored++
+	case StatusTimeout:
+		s.TimedOut++
+	case StatusPanic:
+		s.Panicked++
+	}
+}
+
+// RunSpec runs all the test cases of the specified CommonMark version against
+// "yourFunc" and returns a report of every test case. Unlike SpecCheck, it does
+// not stop at the first failure.
+//
+// The returned error is only for failures of the checker itself, such as an
+// invalid or unsupported spec version. Failing test cases are recorded in the
+// report.
+//
+// Usage:
+//
+//	report, err := mdspec.RunSpec("latest", myFunc)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	fmt.Printf("%d/%d passed (%.1f%%)\n", report.Passed, report.Total, report.PassRate())
+func RunSpec(specVersion string, yourFunc func(string) (string, error), opts ...Option) (*Report, error) {
+	return runSpec(context.Background(), specVersion, toParseFunc(yourFunc), newConfig(opts))
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// runSpec runs the test cases selected by the config and returns the report.
+func runSpec(ctx context.Context, specVersion string, yourFunc parseFunc, conf *config) (*Report, error) {
+	resolvedVer, testCases, err := loadSelectedTestCases(specVersion, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+
+	results := runAllTests(ctx, testCases, yourFunc, conf)
+	if ctx.Err() != nil {
+		return nil, errCanceled(ctx)
+	}
+
+	report := newReport(resolvedVer, results)
+	report.Renderer = conf.renderer
+	report.StartedAt = startedAt
+	report.Duration = time.Since(startedAt)
+
+	return report, nil
+}
+
+// newReport creates a report from the given results and counts the outcomes.
+func newReport(specVersion string, results []Result) *Report {
+	report := &Report{
+		Version: specVersion,
+		Results: results,
+		Total:   len(results),
+	}
+
+	for _, result := range results {
+		switch result.Status {
+		case StatusPass:
+			report.Passed++
+		case StatusFail:
+			report.Failed++
+		case StatusError:
+			report.Errored++
+		case StatusTimeout:
+			report.TimedOut++
+		case StatusPanic:
+			report.Panicked++
+		}
+	}
+
+	return report
+}
+
+// percentage returns the ratio of part to total in percent. It returns 0 if
+// total is 0.
+func percentage(part, total int) float64 {
+	const hundred = 100
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) * hundred / float64(total)
+}
+
+// runAllTests runs all the test cases to completion and returns their results
+// in the same order as the given test cases. On the cancellation of the context,
+// it stops starting new tests and the results are incomplete.
+func runAllTests(ctx context.Context, testCases []TestCase, yourFunc parseFunc, conf *config) []Result {
+	results := make([]Result, len(testCases))
+
+	if conf.maxConcurrency == noConcurrency {
+		for index, testCase := range testCases {
+			if ctx.Err() != nil {
+				break
+			}
+
+			results[index] = runTestCase(ctx, testCase, yourFunc, conf)
+		}
+
+		return results
+	}
+
+	maxConcurrency := conf.maxConcurrency
+	if maxConcurrency == 0 {
+		maxConcurrency = runtime.GOMAXPROCS(0)
+	}
+
+	// The goroutines never return an error, so a plain group is enough. Unlike
+	// errgroup.WithContext, it keeps the context without a Done channel as is,
+	// which lets callFunc call the function directly.
+	var errGroup errgroup.Group
+
+	errGroup.SetLimit(maxConcurrency)
+
+	for index, testCase := range testCases {
+		if ctx.Err() != nil {
+			break
+		}
+
+		errGroup.Go(func() error {
+			// Each goroutine writes to its own index, so no lock is needed.
+			results[index] = runTestCase(ctx, testCase, yourFunc, conf)
+
+			return nil
+		})
+	}
+
+	_ = errGroup.Wait() // goroutines never return an error
+
+	return results
+}
+
+// runTestCase runs a single test case and returns its result.
+func runTestCase(ctx context.Context, testCase TestCase, yourFunc parseFunc, conf *config) Result {
+	result := Result{
+		TestCase:     testCase,
+		Status:       StatusPass,
+		KnownFailure: conf.isKnownFailure(testCase),
+	}
+
+	startedAt := time.Now()
+	out := callFunc(ctx, yourFunc, testCase.Markdown, conf.timeout)
+	result.Duration = time.Since(startedAt)
+	result.Actual, result.Err = out.html, out.err
+
+	if out.panicErr != nil {
+		out.panicErr.TestCase = testCase
+		result.Status = StatusPanic
+		result.Err = out.panicErr
+
+		return result
+	}
+
+	if out.timedOut {
+		result.Status = StatusTimeout
+		result.Err = &TimeoutError{Timeout: conf.timeout, TestCase: testCase}
+
+		return result
+	}
+
+	if result.Err != nil {
+		result.Status = StatusError
+
+		return result
+	}
+
+	if ok, detail := conf.compare(testCase, result.Actual); !ok {
+		result.Status = StatusFail
+		result.Detail = detail
+	}
+
+	return result
+}
@@ -0,0 +1,157 @@
+package mdspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WriteJSON writes the report to "w" as indented JSON, so that it can be archived
+// and read back later with ReadReport.
+//
+// The results extend the JSON schema of the embedded test cases with the
+// "status", "actual", "detail", "error", "known_failure" and "duration_ns"
+// fields. E.g.
+//
+//	{
+//	  "markdown": "\tfoo\tbaz\t\tbim\n",
+//	  "html": "<pre><code>foo\tbaz\t\tbim\n</code></pre>\n",
+//	  "section": "Tabs",
+//	  "start_line": 355,
+//	  "end_line": 360,
+//	  "example": 1,
+//	  "status": "fail",
+//	  "actual": "<p>foo baz bim</p>\n",
+//	  "duration_ns": 5208
+//	}
+//
+// Usage:
+//
+//	report, err := mdspec.RunSpec("latest", myFunc, mdspec.WithRenderer("my-parser v1.2.0"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	if err := report.WriteJSON(file); err != nil {
+//		log.Fatal(err)
+//	}
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return errors.Wrap(encoder.Encode(r), "failed to write JSON report")
+}
+
+// ReadReport reads a report written by WriteJSON.
+//
+// The Err field of the results only holds the error message since the original
+// error types, such as *TimeoutError, are not recorded in JSON.
+func ReadReport(r io.Reader) (*Report, error) {
+	report := &Report{}
+
+	if err := json.NewDecoder(r).Decode(report); err != nil {
+		return nil, errors.Wrap(err, "failed to read JSON report")
+	}
+
+	return report, nil
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns the name of the
+// status such as "pass".
+func (s Status) MarshalText() ([]byte, error) {
+	name := s.String()
+	if name == "unknown" {
+		return nil, errors.Errorf("unknown status: %d", int(s))
+	}
+
+	return []byte(name), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the names
+// returned by String.
+func (s *Status) UnmarshalText(text []byte) error {
+	for status := StatusPass; status <= StatusPanic; status++ {
+		if status.String() == string(text) {
+			*s = status
+
+			return nil
+		}
+	}
+
+	return errors.Errorf("unknown status: %q", text)
+}
+
+// MarshalJSON implements json.Marshaler. See WriteJSON for the format.
+func (r Result) MarshalJSON() ([]byte, error) {
+	raw := resultJSON{
+		TestCase:     r.TestCase,
+		Status:       r.Status,
+		Actual:       r.Actual,
+		Detail:       r.Detail,
+		KnownFailure: r.KnownFailure,
+		Duration:     r.Duration,
+	}
+
+	if r.Err != nil {
+		raw.Error = r.Err.Error()
+	}
+
+	// Keep the HTML readable. json.Marshal would escape "<", ">" and "&".
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(raw); err != nil {
+		return nil, errors.Wrap(err, "failed to marshal result")
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. See WriteJSON for the format.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var raw resultJSON
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return errors.Wrap(err, "failed to unmarshal result")
+	}
+
+	*r = Result{
+		TestCase:     raw.TestCase,
+		Status:       raw.Status,
+		Actual:       raw.Actual,
+		Detail:       raw.Detail,
+		KnownFailure: raw.KnownFailure,
+		Duration:     raw.Duration,
+	}
+
+	if raw.Error != "" {
+		r.Err = errors.New(raw.Error)
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// resultJSON is the JSON form of Result.
+//
+// The test case comes first so that the fields of the spec are followed by the
+// fields of the outcome.
+type resultJSON struct {
+	TestCase
+
+	Status       Status        `json:"status"`
+	Actual       string        `json:"actual"`
+	Detail       string        `json:"detail,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	KnownFailure bool          `json:"known_failure,omitempty"`
+	Duration     time.Duration `json:"duration_ns"`
+}
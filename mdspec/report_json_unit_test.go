@@ -0,0 +1,175 @@
+package mdspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  (*Report).WriteJSON() and ReadReport()
+// ----------------------------------------------------------------------------
+
+func TestReport_WriteJSON_round_trip(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]TestCase{}
+	for _, exampleNum := range []int{1, 2, 3, 4} {
+		testCase := getTestCase(t, "spec_v0.31.2.json", exampleNum)
+		testCases[testCase.Markdown] = testCase
+	}
+
+	myFunc := func(markdown string) (string, error) {
+		switch testCases[markdown].ExampleNum {
+		case 2:
+			return "<p>bad & wrong</p>\n", nil
+		case 3:
+			return "", errors.New("unsupported syntax")
+		case 4:
+			panic("boom")
+		}
+
+		return testCases[markdown].HTML, nil
+	}
+
+	report, err := RunSpec("v0.31.2", myFunc,
+		WithExampleRange(1, 4),
+		WithKnownFailures(2),
+		WithRenderer("my-parser v1.2.0"),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-parser v1.2.0", report.Renderer)
+	assert.False(t, report.StartedAt.IsZero(), "start time should be recorded")
+	assert.Positive(t, report.Duration, "run duration should be recorded")
+
+	var buf bytes.Buffer
+
+	require.NoError(t, report.WriteJSON(&buf))
+
+	jsonReport := buf.String()
+
+	assert.Contains(t, jsonReport, `"renderer": "my-parser v1.2.0"`)
+	assert.Contains(t, jsonReport, `"status": "fail"`, "status should be its name")
+	assert.Contains(t, jsonReport, `"actual": "<p>bad & wrong</p>\n"`, "HTML should not be escaped")
+	assert.Contains(t, jsonReport, `"error": "unsupported syntax"`)
+	assert.Contains(t, jsonReport, `"known_failure": true`)
+	assert.Contains(t, jsonReport, `"start_line": 355`, "test case fields should be inlined")
+
+	readReport, err := ReadReport(&buf)
+	require.NoError(t, err)
+
+	assert.True(t, report.StartedAt.Equal(readReport.StartedAt))
+	assert.Equal(t, report.Version, readReport.Version)
+	assert.Equal(t, report.Renderer, readReport.Renderer)
+	assert.Equal(t, report.Duration, readReport.Duration)
+	assert.Equal(t, report.Sections(), readReport.Sections())
+	assert.Equal(t, 1, readReport.Passed)
+	assert.Equal(t, 1, readReport.Failed)
+	assert.Equal(t, 1, readReport.Errored)
+	assert.Equal(t, 1, readReport.Panicked)
+
+	require.Len(t, readReport.Results, len(report.Results))
+
+	for index, result := range report.Results {
+		readResult := readReport.Results[index]
+
+		assert.Equal(t, result.TestCase, readResult.TestCase)
+		assert.Equal(t, result.Status, readResult.Status)
+		assert.Equal(t, result.Actual, readResult.Actual)
+		assert.Equal(t, result.Detail, readResult.Detail)
+		assert.Equal(t, result.KnownFailure, readResult.KnownFailure)
+		assert.Equal(t, result.Duration, readResult.Duration)
+
+		if result.Err == nil {
+			assert.NoError(t, readResult.Err)
+
+			continue
+		}
+
+		require.Error(t, readResult.Err)
+		assert.Equal(t, result.Err.Error(), readResult.Err.Error(), "error message should be kept")
+	}
+}
+
+func TestReport_WriteJSON_detail(t *testing.T) {
+	t.Parallel()
+
+	report, err := RunSpec("v0.31.2", func(string) (string, error) {
+		return "<p>bad</p>\n", nil
+	}, WithExamples(1), WithNormalizedHTML())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, report.WriteJSON(&buf))
+
+	readReport, err := ReadReport(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, report.Results[0].Detail, readReport.Results[0].Detail)
+	assert.NotEmpty(t, readReport.Results[0].Detail)
+}
+
+func TestReport_WriteJSON_write_error(t *testing.T) {
+	t.Parallel()
+
+	err := (&Report{}).WriteJSON(&limitedWriter{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write JSON report")
+}
+
+func TestReport_WriteJSON_unknown_status(t *testing.T) {
+	t.Parallel()
+
+	report := &Report{Results: []Result{{Status: Status(99)}}}
+
+	err := report.WriteJSON(&bytes.Buffer{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown status: 99")
+}
+
+func TestReadReport_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name      string
+		input     string
+		expectErr string
+	}{
+		{name: "malformed JSON", input: `{"version":`, expectErr: "failed to read JSON report"},
+		{name: "malformed result", input: `{"results":[[]]}`, expectErr: "failed to unmarshal result"},
+		{name: "unknown status", input: `{"results":[{"status":"flaky"}]}`, expectErr: `unknown status: "flaky"`},
+	} {
+		_, err := ReadReport(strings.NewReader(test.input))
+
+		require.Error(t, err, test.name)
+		assert.Contains(t, err.Error(), test.expectErr, test.name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Status
+// ----------------------------------------------------------------------------
+
+func TestStatus_MarshalText(t *testing.T) {
+	t.Parallel()
+
+	for _, status := range []Status{StatusPass, StatusFail, StatusError, StatusTimeout, StatusPanic} {
+		data, err := json.Marshal(status)
+		require.NoError(t, err)
+
+		var decoded Status
+
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, status, decoded, "status should survive the round trip")
+		assert.JSONEq(t, `"`+status.String()+`"`, string(data))
+	}
+}
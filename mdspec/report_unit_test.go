@@ -0,0 +1,209 @@
+package mdspec
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  RunSpec()
+// ----------------------------------------------------------------------------
+
+func TestRunSpec_golden(t *testing.T) {
+	t.Parallel()
+
+	testCases, _ := prepareTestCasesMap(t, oldestSpecFile)
+
+	for _, maxConcurrency := range []int{-1, 0, 3} {
+		report, err := RunSpec("v0.13", getGoldenParser(t, "v0.13"), WithConcurrency(maxConcurrency))
+		require.NoError(t, err)
+
+		assert.Equal(t, "v0.13", report.Version)
+		assert.Len(t, report.Results, len(testCases))
+		assert.Equal(t, len(testCases), report.Total)
+		assert.Equal(t, len(testCases), report.Passed)
+		assert.Zero(t, report.Failed)
+		assert.Zero(t, report.Errored)
+		assert.Empty(t, report.Failures())
+		assert.InDelta(t, 100.0, report.PassRate(), 0.0001)
+
+		for index, result := range report.Results {
+			require.Equal(t, testCases[index], result.TestCase, "results should be in spec order")
+			require.True(t, result.Passed())
+		}
+	}
+}
+
+func TestRunSpec_latest(t *testing.T) {
+	t.Parallel()
+
+	latestVer, err := LatestVersion()
+	require.NoError(t, err)
+
+	report, err := RunSpec("latest", getGoldenParser(t, latestVer))
+	require.NoError(t, err)
+
+	assert.Equal(t, latestVer, report.Version, "latest should be resolved")
+	assert.Equal(t, report.Total, report.Passed)
+}
+
+func TestRunSpec_all_failures(t *testing.T) {
+	t.Parallel()
+
+	testCases, expectedResults := prepareTestCasesMap(t, oldestSpecFile)
+
+	// Only the first test case returns the expected HTML.
+	myFunc := func(markdown string) (string, error) {
+		if markdown == testCases[0].Markdown {
+			return expectedResults[markdown], nil
+		}
+
+		return "<p>bad HTML</p>", nil
+	}
+
+	report, err := RunSpec("v0.13", myFunc, WithConcurrency(-1))
+	require.NoError(t, err)
+
+	require.True(t, report.Results[0].Passed())
+	assert.Equal(t, 1, report.Passed)
+	assert.Equal(t, len(testCases)-1, report.Failed, "every failure should be recorded")
+	assert.Zero(t, report.Errored)
+	assert.Len(t, report.Failures(), len(testCases)-1)
+	assert.Less(t, report.PassRate(), 100.0)
+
+	for _, result := range report.Failures() {
+		assert.Equal(t, StatusFail, result.Status)
+		assert.Equal(t, "<p>bad HTML</p>", result.Actual)
+		require.NoError(t, result.Err)
+	}
+}
+
+func TestRunSpec_function_error(t *testing.T) {
+	t.Parallel()
+
+	report, err := RunSpec("v0.13", func(string) (string, error) {
+		return "partial", errors.New("something went wrong")
+	})
+	require.NoError(t, err, "function errors should be recorded in the report")
+
+	assert.Equal(t, report.Total, report.Errored)
+	assert.Zero(t, report.Passed)
+	assert.Zero(t, report.PassRate())
+
+	for _, result := range report.Results {
+		assert.Equal(t, StatusError, result.Status)
+		assert.Equal(t, "partial", result.Actual)
+		require.EqualError(t, result.Err, "something went wrong")
+	}
+}
+
+func TestRunSpec_invalid_version(t *testing.T) {
+	t.Parallel()
+
+	dummyFunc := func(string) (string, error) {
+		return "", nil
+	}
+
+	report, err := RunSpec("version Unknown", dummyFunc)
+
+	require.Error(t, err)
+	require.Nil(t, report)
+	assert.Contains(t, err.Error(), "invalid spec version format")
+
+	report, err = RunSpec("v0.1", dummyFunc)
+
+	require.Error(t, err)
+	require.Nil(t, report)
+	assert.Contains(t, err.Error(), "spec file not found")
+}
+
+// ----------------------------------------------------------------------------
+//  Report.Sections()
+// ----------------------------------------------------------------------------
+
+func TestReport_Sections(t *testing.T) {
+	t.Parallel()
+
+	testCases, expectedResults := prepareTestCasesMap(t, oldestSpecFile)
+	failingMarkdown := testCases[0].Markdown
+
+	// Collect the expected totals per section in spec order
+	expect := []SectionResult{}
+
+	for _, testCase := range testCases {
+		index := slices.IndexFunc(expect, func(s SectionResult) bool {
+			return s.Name == testCase.Section
+		})
+		if index < 0 {
+			index = len(expect)
+			expect = append(expect, SectionResult{Name: testCase.Section})
+		}
+
+		if testCase.Markdown == failingMarkdown {
+			expect[index].count(StatusError)
+		} else {
+			expect[index].count(StatusPass)
+		}
+	}
+
+	// Function that fails only on the markdown of the first example.
+	myFunc := func(markdown string) (string, error) {
+		if markdown == failingMarkdown {
+			return "", errors.New("not implemented")
+		}
+
+		return expectedResults[markdown], nil
+	}
+
+	report, err := RunSpec("v0.13", myFunc)
+	require.NoError(t, err)
+
+	sections := report.Sections()
+	require.Equal(t, expect, sections, "sections should be in spec order with totals")
+	assert.Positive(t, sections[0].Errored, "first section should contain the failure")
+	assert.Less(t, sections[0].PassRate(), 100.0)
+
+	total := 0
+	for _, section := range sections {
+		total += section.Total
+	}
+
+	assert.Equal(t, report.Total, total, "every result should belong to a section")
+}
+
+func TestReport_Sections_empty(t *testing.T) {
+	t.Parallel()
+
+	report := newReport("v0.13", nil)
+
+	require.NotNil(t, report.Sections())
+	require.Empty(t, report.Sections())
+}
+
+// ----------------------------------------------------------------------------
+//  Status
+// ----------------------------------------------------------------------------
+
+func TestStatus_String(t *testing.T) {
+	t.Parallel()
+
+	for status, expect := range map[Status]string{
+		StatusPass:  "pass",
+		StatusFail:  "fail",
+		StatusError: "error",
+		Status(-1):  "unknown",
+	} {
+		assert.Equal(t, expect, status.String())
+	}
+}
+
+func Test_percentage_zero_total(t *testing.T) {
+	t.Parallel()
+
+	assert.Zero(t, percentage(0, 0), "zero total should not divide by zero")
+	assert.InDelta(t, 50.0, percentage(1, 2), 0.0001)
+}
@@ -0,0 +1,143 @@
+package mdspec
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// RunT runs the test cases of the specified CommonMark version against "yourFunc"
+// as subtests of "t". A subtest is registered per spec section and per example,
+// such as "Tabs/example_1", so sections and examples can be selected with the
+// "-run" flag of "go test" and each of them is listed with "-v".
+//
+// Note that "go test" replaces spaces in subtest names with underscores. E.g.
+// "Link reference definitions" becomes "Link_reference_definitions".
+//
+// Examples listed in WithKnownFailures pass if they fail and fail if they pass.
+// With WithBaseline, the examples that did not pass in the baseline always pass.
+//
+// With WithThresholds, the failures of the examples are only logged and the test
+// fails if the pass rates are below the thresholds. If "-run" selects a subset of
+// the examples, the thresholds of the sections that did not run are ignored.
+//
+// Use WithTimeout to fail the examples that hang instead of the whole test
+// binary.
+//
+// Unless "WithConcurrency(-1)" is given, the example subtests run in parallel
+// within the limit of the "-parallel" flag of "go test".
+//
+// Usage:
+//
+//	func TestSpec(t *testing.T) {
+//		mdspec.RunT(t, "latest", myFunc)
+//	}
+//
+//	// go test -run 'TestSpec/Tabs' -v
+func RunT(t *testing.T, specVersion string, yourFunc func(string) (string, error), opts ...Option) {
+	t.Helper()
+
+	conf := newConfig(opts)
+
+	resolvedVer, testCases, err := loadSelectedTestCases(specVersion, conf)
+	if err != nil {
+		t.Fatalf("failed to load test cases: %v", err)
+	}
+
+	parse := toParseFunc(yourFunc)
+	recorder := &resultRecorder{}
+
+	for _, section := range groupBySection(testCases) {
+		t.Run(section[0].Section, func(t *testing.T) {
+			for _, testCase := range section {
+				t.Run(fmt.Sprintf("example_%d", testCase.ExampleNum), func(t *testing.T) {
+					if conf.maxConcurrency != noConcurrency {
+						t.Parallel()
+					}
+
+					if len(conf.thresholds) > 0 {
+						recorder.record(t, runTestCase(t.Context(), testCase, parse, conf))
+
+						return
+					}
+
+					if err := runCheckedTest(t.Context(), testCase, parse, conf); err != nil {
+						t.Error(err)
+					}
+				})
+			}
+		})
+	}
+
+	// The parallel subtests are done once their parent t.Run returns.
+	if len(conf.thresholds) > 0 {
+		thresholds := conf.thresholds
+		if len(recorder.results) < len(testCases) {
+			// Some subtests were filtered out by the "-run" flag of "go test"
+			thresholds = ranThresholds(thresholds, recorder.results)
+		}
+
+		if err := newReport(resolvedVer, recorder.results).CheckThresholds(thresholds...); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// resultRecorder collects the results of the subtests in the threshold mode.
+type resultRecorder struct {
+	results []Result
+	mutex   sync.Mutex
+}
+
+// record adds the result and logs it if it did not pass. The failure itself is
+// tolerated since only the pass rates matter in the threshold mode.
+func (rec *resultRecorder) record(t *testing.T, result Result) {
+	t.Helper()
+
+	if !result.Passed() {
+		t.Logf("tolerated in the threshold mode: example %d %s", result.TestCase.ExampleNum, result.Status)
+	}
+
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+
+	rec.results = append(rec.results, result)
+}
+
+// ranThresholds returns the thresholds of the sections that have results. The
+// threshold of all the test cases is kept if any result exists.
+func ranThresholds(thresholds []Threshold, results []Result) []Threshold {
+	ranSections := map[string]bool{}
+	for _, result := range results {
+		ranSections[result.TestCase.Section] = true
+	}
+
+	return slices.DeleteFunc(slices.Clone(thresholds), func(threshold Threshold) bool {
+		if threshold.Section == "" {
+			return len(results) == 0
+		}
+
+		return !ranSections[threshold.Section]
+	})
+}
+
+// groupBySection groups the test cases by section keeping the spec order.
+func groupBySection(testCases []TestCase) [][]TestCase {
+	groups := [][]TestCase{}
+	indexes := map[string]int{}
+
+	for _, testCase := range testCases {
+		index, ok := indexes[testCase.Section]
+		if !ok {
+			index = len(groups)
+			indexes[testCase.Section] = index
+
+			groups = append(groups, []TestCase{})
+		}
+
+		groups[index] = append(groups[index], testCase)
+	}
+
+	return groups
+}
@@ -0,0 +1,120 @@
+package mdspec
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// envRunTHelper is the environment variable to run TestRunT_helper_process as
+// a helper process of TestRunT_failure.
+const envRunTHelper = "MDSPEC_RUNT_HELPER"
+
+// ----------------------------------------------------------------------------
+//  RunT()
+// ----------------------------------------------------------------------------
+
+func TestRunT_golden(t *testing.T) {
+	t.Parallel()
+
+	RunT(t, "v0.13", getGoldenParser(t, "v0.13"))
+	RunT(t, "latest", getGoldenParser(t, "v0.31.2"), WithConcurrency(-1))
+}
+
+func TestRunT_failure(t *testing.T) {
+	t.Parallel()
+
+	//nolint:gosec // the command is the test binary itself
+	cmd := exec.CommandContext(t.Context(), os.Args[0],
+		"-test.run", "^TestRunT_helper_process$", "-test.v")
+	cmd.Env = append(os.Environ(), envRunTHelper+"=1")
+
+	out, err := cmd.CombinedOutput()
+	require.Error(t, err, "the helper process should fail")
+
+	output := string(out)
+
+	// Failing examples are reported as individual subtests
+	assert.Contains(t, output, "--- FAIL: TestRunT_helper_process/Tabs/example_1 ")
+	assert.Contains(t, output, "--- FAIL: TestRunT_helper_process/Tabs/example_2 ")
+	assert.Contains(t, output, "did not return the expected HTML result")
+	// Panics are reported as failures of the example
+	assert.Contains(t, output, "--- FAIL: TestRunT_helper_process/Tabs/example_3 ")
+	assert.Contains(t, output, "the given function panicked: boom")
+	// Section names with spaces are converted by the testing package
+	assert.Contains(t, output, "TestRunT_helper_process/Backslash_escapes/example_")
+	// Other sections are not affected
+	assert.NotContains(t, output, "--- FAIL: TestRunT_helper_process/Backslash_escapes")
+}
+
+// TestRunT_helper_process is not a real test. It fails the first two examples
+// of v0.31.2, panics on the third one and is used as a helper process of
+// TestRunT_failure.
+func TestRunT_helper_process(t *testing.T) {
+	t.Parallel()
+
+	if os.Getenv(envRunTHelper) != "1" {
+		t.Skip("helper process for TestRunT_failure")
+	}
+
+	testCases, expectedResults := prepareTestCasesMap(t, "spec_v0.31.2.json")
+
+	RunT(t, "v0.31.2", func(markdown string) (string, error) {
+		if markdown == testCases[0].Markdown || markdown == testCases[1].Markdown {
+			return "<p>bad HTML</p>", nil
+		}
+
+		if markdown == testCases[2].Markdown {
+			panic("boom")
+		}
+
+		return expectedResults[markdown], nil
+	})
+}
+
+// ----------------------------------------------------------------------------
+//  ranThresholds()
+// ----------------------------------------------------------------------------
+
+func Test_ranThresholds(t *testing.T) {
+	t.Parallel()
+
+	thresholds := []Threshold{
+		{MinPassRate: 90},
+		{Section: "Tabs", MinPassRate: 100},
+		{Section: "Backslash escapes", MinPassRate: 100},
+	}
+	results := []Result{{TestCase: TestCase{Section: "Backslash escapes", ExampleNum: 12}}}
+
+	assert.Equal(t, []Threshold{thresholds[0], thresholds[2]}, ranThresholds(thresholds, results),
+		"the thresholds of the sections without results should be removed")
+	assert.Empty(t, ranThresholds(thresholds, nil), "no threshold should be kept without results")
+	assert.Len(t, thresholds, 3, "the given thresholds should not be modified")
+}
+
+// ----------------------------------------------------------------------------
+//  groupBySection()
+// ----------------------------------------------------------------------------
+
+func Test_groupBySection(t *testing.T) {
+	t.Parallel()
+
+	testCases := []TestCase{
+		{Section: "A", ExampleNum: 1},
+		{Section: "B", ExampleNum: 2},
+		{Section: "A", ExampleNum: 3},
+		{Section: "C", ExampleNum: 4},
+	}
+
+	expect := [][]TestCase{
+		{{Section: "A", ExampleNum: 1}, {Section: "A", ExampleNum: 3}},
+		{{Section: "B", ExampleNum: 2}},
+		{{Section: "C", ExampleNum: 4}},
+	}
+
+	require.Equal(t, expect, groupBySection(testCases))
+	require.Empty(t, groupBySection(nil))
+}
@@ -0,0 +1,229 @@
+package mdspec
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ChangeKind represents the kind of change of an example between two specs.
+type ChangeKind int
+
+const (
+	// ChangeAdded means the example is only in the new spec.
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved means the example is only in the old spec.
+	ChangeRemoved
+	// ChangeModified means the example is in both specs but its expected HTML
+	// or its section changed.
+	ChangeModified
+)
+
+// String returns the name of the change kind.
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+
+	return "unknown"
+}
+
+// SpecChange is the change of an example between two specs.
+type SpecChange struct {
+	// Old is the example in the old spec. It is the zero value if the example
+	// was added.
+	Old TestCase
+	// New is the example in the new spec. It is the zero value if the example
+	// was removed.
+	New TestCase
+	// Kind is the kind of the change.
+	Kind ChangeKind
+}
+
+// HTMLChanged returns true if the expected HTML of a modified example changed.
+func (c SpecChange) HTMLChanged() bool {
+	return c.Kind == ChangeModified && c.Old.HTML != c.New.HTML
+}
+
+// Moved returns true if a modified example moved to another section.
+func (c SpecChange) Moved() bool {
+	return c.Kind == ChangeModified && c.Old.Section != c.New.Section
+}
+
+// SpecDiff is the difference between the examples of two specs. See
+// DiffVersions.
+type SpecDiff struct {
+	// From is the version or the name of the suite of the old spec.
+	From string
+	// To is the version or the name of the suite of the new spec.
+	To string
+	// Changes holds the added and modified examples in the order of the new
+	// spec, followed by the removed examples in the order of the old spec.
+	Changes []SpecChange
+	// Unchanged is the number of the examples with the same expected HTML in
+	// the same section. Their example numbers may differ.
+	Unchanged int
+}
+
+// DiffVersions compares the examples of two embedded spec versions. "latest"
+// and version constraints are resolved, see ResolveVersion.
+//
+// Since the example numbers shift between versions, the examples are matched
+// on their Markdown. An example whose Markdown changed is reported as removed
+// from the old spec and added to the new one.
+//
+// Usage:
+//
+//	diff, err := mdspec.DiffVersions("v0.30", "v0.31.2")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	for _, change := range diff.Changes {
+//		fmt.Println(change.Kind, change.Old.ExampleNum, change.New.ExampleNum)
+//	}
+func DiffVersions(from, to string) (*SpecDiff, error) {
+	suiteFrom, err := LoadSuite(from)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the old spec")
+	}
+
+	suiteTo, err := LoadSuite(to)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the new spec")
+	}
+
+	return DiffSuites(suiteFrom, suiteTo), nil
+}
+
+// DiffSuites compares the examples of two suites like DiffVersions. Use it to
+// compare custom suites, such as a forked spec and the CommonMark spec.
+func DiffSuites(from, to *Suite) *SpecDiff {
+	diff := &SpecDiff{From: from.Name, To: to.Name, Changes: []SpecChange{}}
+
+	// Examples with the same Markdown are matched in spec order
+	unmatched := map[string][]int{}
+	for index, testCase := range from.testCases {
+		unmatched[testCase.Markdown] = append(unmatched[testCase.Markdown], index)
+	}
+
+	matched := make([]bool, len(from.testCases))
+
+	for _, newCase := range to.testCases {
+		indexes := unmatched[newCase.Markdown]
+		if len(indexes) == 0 {
+			diff.Changes = append(diff.Changes, SpecChange{New: newCase, Kind: ChangeAdded})
+
+			continue
+		}
+
+		unmatched[newCase.Markdown] = indexes[1:]
+		matched[indexes[0]] = true
+		oldCase := from.testCases[indexes[0]]
+
+		if oldCase.HTML == newCase.HTML && oldCase.Section == newCase.Section {
+			diff.Unchanged++
+
+			continue
+		}
+
+		diff.Changes = append(diff.Changes, SpecChange{Old: oldCase, New: newCase, Kind: ChangeModified})
+	}
+
+	for index, oldCase := range from.testCases {
+		if !matched[index] {
+			diff.Changes = append(diff.Changes, SpecChange{Old: oldCase, Kind: ChangeRemoved})
+		}
+	}
+
+	return diff
+}
+
+// Count returns the number of the changes of the kind.
+func (d *SpecDiff) Count(kind ChangeKind) int {
+	count := 0
+
+	for _, change := range d.Changes {
+		if change.Kind == kind {
+			count++
+		}
+	}
+
+	return count
+}
+
+// WriteText writes the changes to "w" in a human readable format. The added and
+// removed examples are shown with their Markdown and expected HTML, and the
+// expected HTML of the modified examples as a unified diff. See Diff for the
+// format. E.g.
+//
+//	spec v0.25 -> v0.26: 17 added, 15 removed, 8 modified, 593 unchanged
+//	...
+//
+//	~ example 263 -> 265 (Lists): modified
+//	  markdown: "The number of windows in my house is\n14.  The number of doors is 6.\n"
+//	  --- v0.25
+//	  +++ v0.26
+//	  @@ -1,4 +1,2 @@
+//	  -<p>The number of windows in my house is</p>
+//	  -<ol start="14">
+//	  -<li>The number of doors is 6.</li>
+//	  -</ol>
+//	  +<p>The number of windows in my house is
+//	  +14.  The number of doors is 6.</p>
+//	...
+func (d *SpecDiff) WriteText(w io.Writer) error {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "spec %s -> %s: %d added, %d removed, %d modified, %d unchanged\n",
+		d.From, d.To, d.Count(ChangeAdded), d.Count(ChangeRemoved), d.Count(ChangeModified), d.Unchanged)
+
+	for _, change := range d.Changes {
+		out.WriteString("\n")
+		d.writeChange(&out, change)
+	}
+
+	_, err := io.WriteString(w, out.String())
+
+	return errors.Wrap(err, "failed to write spec diff")
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// writeChange writes a change in the format of WriteText.
+func (d *SpecDiff) writeChange(out *strings.Builder, change SpecChange) {
+	switch change.Kind {
+	case ChangeAdded:
+		fmt.Fprintf(out, "+ example %d (%s): added\n", change.New.ExampleNum, change.New.Section)
+		fmt.Fprintf(out, "  markdown: %#v\n  html:     %#v\n", change.New.Markdown, change.New.HTML)
+	case ChangeRemoved:
+		fmt.Fprintf(out, "- example %d (%s): removed\n", change.Old.ExampleNum, change.Old.Section)
+		fmt.Fprintf(out, "  markdown: %#v\n  html:     %#v\n", change.Old.Markdown, change.Old.HTML)
+	default:
+		section := change.New.Section
+		if change.Moved() {
+			section = change.Old.Section + " -> " + change.New.Section
+		}
+
+		fmt.Fprintf(out, "~ example %d -> %d (%s): modified\n", change.Old.ExampleNum, change.New.ExampleNum, section)
+		fmt.Fprintf(out, "  markdown: %#v\n", change.New.Markdown)
+
+		if change.HTMLChanged() {
+			out.WriteString(indentLines(diffLabeled(d.From, d.To, change.Old.HTML, change.New.HTML), "  ") + "\n")
+		}
+	}
+}
+
+// indentLines prefixes each line of the text with the indent.
+func indentLines(text, indent string) string {
+	return indent + strings.ReplaceAll(text, "\n", "\n"+indent)
+}
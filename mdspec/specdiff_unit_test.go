@@ -0,0 +1,157 @@
+package mdspec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  DiffVersions()
+// ----------------------------------------------------------------------------
+
+func TestDiffVersions(t *testing.T) {
+	t.Parallel()
+
+	diff, err := DiffVersions("v0.27", "v0.28")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.27", diff.From)
+	assert.Equal(t, "v0.28", diff.To)
+	assert.Equal(t, 6, diff.Count(ChangeAdded))
+	assert.Equal(t, 4, diff.Count(ChangeRemoved))
+	assert.Equal(t, 4, diff.Count(ChangeModified))
+	assert.Equal(t, 614, diff.Unchanged)
+
+	// "``\nfoo\n``\n" moved from "Code spans" to "Fenced code blocks"
+	change := diff.Changes[0]
+
+	assert.Equal(t, ChangeModified, change.Kind)
+	assert.Equal(t, 315, change.Old.ExampleNum)
+	assert.Equal(t, 90, change.New.ExampleNum)
+	assert.True(t, change.Moved())
+	assert.False(t, change.HTMLChanged())
+
+	// Same version
+	diff, err = DiffVersions("v0.31.2", "latest")
+	require.NoError(t, err)
+
+	assert.Empty(t, diff.Changes)
+	assert.Equal(t, 652, diff.Unchanged)
+}
+
+func TestDiffVersions_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := DiffVersions("unknown", "v0.30")
+	require.ErrorIs(t, err, ErrInvalidVersion)
+	assert.Contains(t, err.Error(), "failed to load the old spec")
+
+	_, err = DiffVersions("v0.30", "v0.99")
+	require.ErrorIs(t, err, ErrSpecNotFound)
+	assert.Contains(t, err.Error(), "failed to load the new spec")
+}
+
+// ----------------------------------------------------------------------------
+//  DiffSuites()
+// ----------------------------------------------------------------------------
+
+func TestDiffSuites(t *testing.T) {
+	t.Parallel()
+
+	oldSuite, err := NewSuite("old", []TestCase{
+		{Markdown: "same\n", HTML: "<p>same</p>\n", Section: "A", ExampleNum: 1},
+		{Markdown: "html\n", HTML: "<p>old</p>\n", Section: "A", ExampleNum: 2},
+		{Markdown: "moved\n", HTML: "<p>moved</p>\n", Section: "A", ExampleNum: 3},
+		{Markdown: "removed\n", HTML: "<p>removed</p>\n", Section: "B", ExampleNum: 4},
+		{Markdown: "dup\n", HTML: "<p>dup 1</p>\n", Section: "B", ExampleNum: 5},
+		{Markdown: "dup\n", HTML: "<p>dup 2</p>\n", Section: "B", ExampleNum: 6},
+	})
+	require.NoError(t, err)
+
+	newSuite, err := NewSuite("new", []TestCase{
+		{Markdown: "added\n", HTML: "<p>added</p>\n", Section: "A", ExampleNum: 1},
+		{Markdown: "same\n", HTML: "<p>same</p>\n", Section: "A", ExampleNum: 2},
+		{Markdown: "html\n", HTML: "<p>new</p>\n", Section: "A", ExampleNum: 3},
+		{Markdown: "dup\n", HTML: "<p>dup 1</p>\n", Section: "B", ExampleNum: 4},
+		{Markdown: "moved\n", HTML: "<p>moved</p>\n", Section: "C", ExampleNum: 5},
+	})
+	require.NoError(t, err)
+
+	diff := DiffSuites(oldSuite, newSuite)
+
+	assert.Equal(t, 2, diff.Unchanged, "renumbered examples should be unchanged")
+
+	require.Len(t, diff.Changes, 5)
+
+	for index, expect := range []struct {
+		kind        ChangeKind
+		oldNum      int
+		newNum      int
+		htmlChanged bool
+		moved       bool
+	}{
+		{kind: ChangeAdded, newNum: 1},
+		{kind: ChangeModified, oldNum: 2, newNum: 3, htmlChanged: true},
+		{kind: ChangeModified, oldNum: 3, newNum: 5, moved: true},
+		{kind: ChangeRemoved, oldNum: 4},
+		{kind: ChangeRemoved, oldNum: 6},
+	} {
+		change := diff.Changes[index]
+
+		assert.Equal(t, expect.kind, change.Kind, "change #%d", index)
+		assert.Equal(t, expect.oldNum, change.Old.ExampleNum, "change #%d", index)
+		assert.Equal(t, expect.newNum, change.New.ExampleNum, "change #%d", index)
+		assert.Equal(t, expect.htmlChanged, change.HTMLChanged(), "change #%d", index)
+		assert.Equal(t, expect.moved, change.Moved(), "change #%d", index)
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, diff.WriteText(&buf))
+	assert.Equal(t, `spec old -> new: 1 added, 2 removed, 2 modified, 2 unchanged
+
++ example 1 (A): added
+  markdown: "added\n"
+  html:     "<p>added</p>\n"
+
+~ example 2 -> 3 (A): modified
+  markdown: "html\n"
+  --- old
+  +++ new
+  @@ -1 +1 @@
+  -<p>old</p>
+  +<p>new</p>
+
+~ example 3 -> 5 (A -> C): modified
+  markdown: "moved\n"
+
+- example 4 (B): removed
+  markdown: "removed\n"
+  html:     "<p>removed</p>\n"
+
+- example 6 (B): removed
+  markdown: "dup\n"
+  html:     "<p>dup 2</p>\n"
+`, buf.String())
+}
+
+func TestSpecDiff_WriteText_write_error(t *testing.T) {
+	t.Parallel()
+
+	err := (&SpecDiff{}).WriteText(&limitedWriter{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write spec diff")
+}
+
+func TestChangeKind_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "added", ChangeAdded.String())
+	assert.Equal(t, "removed", ChangeRemoved.String())
+	assert.Equal(t, "modified", ChangeModified.String())
+	assert.Equal(t, "unknown", ChangeKind(99).String())
+}
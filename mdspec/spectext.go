@@ -0,0 +1,146 @@
+package mdspec
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseSpecText parses a spec written in the literate format of the CommonMark
+// spec, "spec.txt", into test cases. It is the format of cmark-gfm,
+// commonmark-extensions and other Markdown dialects.
+//
+// The test cases are computed the same way as "spec_tests.py" of the CommonMark
+// spec repository does:
+//
+//   - An example is a block fenced by 32 backticks followed by " example" and 32
+//     backticks, where a "." line separates the markdown and the HTML.
+//   - The words after " example" in the opening fence are the extensions. They
+//     are joined with a space in TestCase.Extensions.
+//   - "→" is replaced with a tab.
+//   - The section is the last ATX heading ("#" to "######") before the example.
+//   - The example numbers start from 1 and the line numbers are 1-based. The
+//     start line is the line of the opening fence and the end line is the one of
+//     the closing fence.
+//
+// Usage:
+//
+//	file, err := os.Open("spec.txt")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer file.Close()
+//
+//	testCases, err := mdspec.ParseSpecText(file)
+func ParseSpecText(r io.Reader) ([]TestCase, error) {
+	parser := &specTextParser{testCases: []TestCase{}}
+	reader := bufio.NewReader(r)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			parser.parseLine(line)
+		}
+
+		if errors.Is(err, io.EOF) {
+			return parser.testCases, nil
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read spec text")
+		}
+	}
+}
+
+// ReadSuiteText reads a suite from "r" in the format of ParseSpecText.
+func ReadSuiteText(name string, r io.Reader) (*Suite, error) {
+	testCases, err := ParseSpecText(r)
+	if err != nil {
+		return nil, errors.Wrap(err, name)
+	}
+
+	return NewSuite(name, testCases)
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+const (
+	// specFence is the fence of an example in spec.txt.
+	specFence = "````````````````````````````````"
+	// specFenceOpen is the opening fence of an example in spec.txt.
+	specFenceOpen = specFence + " example"
+)
+
+var (
+	// specHeadingRe matches the line of an ATX heading in spec.txt.
+	specHeadingRe = regexp.MustCompile(`^#+ `)
+	// specHeadingMarkRe matches the heading markers to remove from the section
+	// name. Like spec_tests.py, the markers in the middle of the line are also
+	// removed.
+	specHeadingMarkRe = regexp.MustCompile(`#+ `)
+)
+
+// specTextState is the state of specTextParser.
+type specTextState int
+
+const (
+	stateText specTextState = iota
+	stateMarkdown
+	stateHTML
+)
+
+// specTextParser is a line by line port of get_tests() in spec_tests.py.
+type specTextParser struct {
+	section    string
+	markdown   strings.Builder
+	html       strings.Builder
+	extensions ExtensionList
+	testCases  []TestCase
+	numLine    int
+	startLine  int
+	state      specTextState
+}
+
+// parseLine parses a line with the trailing newline, if any.
+func (p *specTextParser) parseLine(line string) {
+	p.numLine++
+
+	trimmed := strings.TrimSpace(line)
+
+	switch {
+	case strings.HasPrefix(trimmed, specFenceOpen):
+		p.state = stateMarkdown
+		p.extensions = ExtensionList(strings.Join(strings.Fields(trimmed[len(specFenceOpen):]), " "))
+	case trimmed == specFence:
+		p.state = stateText
+		p.testCases = append(p.testCases, TestCase{
+			Markdown:   strings.ReplaceAll(p.markdown.String(), "→", "\t"),
+			HTML:       strings.ReplaceAll(p.html.String(), "→", "\t"),
+			Section:    p.section,
+			StartLine:  p.startLine,
+			EndLine:    p.numLine,
+			ExampleNum: len(p.testCases) + 1,
+			Extensions: p.extensions,
+		})
+		p.startLine = 0
+		p.markdown.Reset()
+		p.html.Reset()
+	case trimmed == ".":
+		p.state = stateHTML
+	case p.state == stateMarkdown:
+		if p.startLine == 0 {
+			p.startLine = p.numLine - 1
+		}
+
+		p.markdown.WriteString(line)
+	case p.state == stateHTML:
+		p.html.WriteString(line)
+	case p.state == stateText && specHeadingRe.MatchString(line):
+		p.section = strings.TrimSpace(specHeadingMarkRe.ReplaceAllString(line, ""))
+	}
+}
@@ -0,0 +1,166 @@
+package mdspec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialectText is a spec in the spec.txt format for testing.
+const dialectText = "---\n" +
+	"title: My Dialect Spec\n" +
+	"...\n" +
+	"\n" +
+	"# Introduction\n" +
+	"\n" +
+	"Text with ```` in it.\n" +
+	"\n" +
+	"## Tabs ##\n" +
+	"\n" +
+	"```````````````````````````````` example\n" + // line 11
+	"→foo\n" +
+	".\n" +
+	"<pre><code>foo\n" +
+	"</code></pre>\n" +
+	"````````````````````````````````\n" + // line 16
+	"\n" +
+	"# Tables (extension)\n" +
+	"\n" +
+	"```````````````````````````````` example table strikethrough\n" + // line 20
+	"| a |\n" +
+	"| - |\n" +
+	".\n" +
+	"<table>\n" +
+	"</table>\n" +
+	"````````````````````````````````\n" + // line 26
+	"\n" +
+	"Not a heading: #foo\n" +
+	"\n" +
+	"```````````````````````````````` example\n" +
+	"&amp;\n" +
+	".\n" +
+	"<p>&amp;amp;</p>\n" +
+	"````````````````````````````````" // line 34, without the trailing newline
+
+// ----------------------------------------------------------------------------
+//  ParseSpecText()
+// ----------------------------------------------------------------------------
+
+func TestParseSpecText(t *testing.T) {
+	t.Parallel()
+
+	testCases, err := ParseSpecText(strings.NewReader(dialectText))
+	require.NoError(t, err)
+
+	// The expected values are the output of "spec_tests.py --dump-tests"
+	assert.Equal(t, []TestCase{
+		{
+			Markdown:   "\tfoo\n",
+			HTML:       "<pre><code>foo\n</code></pre>\n",
+			Section:    "Tabs ##",
+			StartLine:  11,
+			EndLine:    16,
+			ExampleNum: 1,
+		},
+		{
+			Markdown:   "| a |\n| - |\n",
+			HTML:       "<table>\n</table>\n",
+			Section:    "Tables (extension)",
+			StartLine:  20,
+			EndLine:    26,
+			ExampleNum: 2,
+			Extensions: "table strikethrough",
+		},
+		{
+			Markdown:   "&amp;\n",
+			HTML:       "<p>&amp;amp;</p>\n",
+			Section:    "Tables (extension)",
+			StartLine:  30,
+			EndLine:    34,
+			ExampleNum: 3,
+		},
+	}, testCases)
+}
+
+func TestParseSpecText_CRLF(t *testing.T) {
+	t.Parallel()
+
+	testCases, err := ParseSpecText(strings.NewReader(strings.ReplaceAll(dialectText, "\n", "\r\n")))
+	require.NoError(t, err)
+
+	require.Len(t, testCases, 3)
+	assert.Equal(t, "\tfoo\r\n", testCases[0].Markdown, "line endings should be kept as is")
+	assert.Equal(t, "Tabs ##", testCases[0].Section)
+	assert.Equal(t, 26, testCases[1].EndLine)
+}
+
+func TestParseSpecText_no_examples(t *testing.T) {
+	t.Parallel()
+
+	testCases, err := ParseSpecText(strings.NewReader("# Introduction\n\nNo examples.\n"))
+	require.NoError(t, err)
+
+	assert.NotNil(t, testCases)
+	assert.Empty(t, testCases)
+}
+
+func TestParseSpecText_read_error(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseSpecText(iotest.TimeoutReader(strings.NewReader(dialectText)))
+
+	require.ErrorIs(t, err, iotest.ErrTimeout)
+	assert.Contains(t, err.Error(), "failed to read spec text")
+}
+
+// ----------------------------------------------------------------------------
+//  ReadSuiteText(), LoadSuiteFile() and LoadSuiteFS()
+// ----------------------------------------------------------------------------
+
+func TestReadSuiteText(t *testing.T) {
+	t.Parallel()
+
+	suite, err := ReadSuiteText("my-dialect", strings.NewReader(dialectText))
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-dialect", suite.Name)
+	assert.Len(t, suite.Cases(), 3)
+
+	_, err = ReadSuiteText("my-dialect", strings.NewReader("# No examples\n"))
+	require.ErrorIs(t, err, ErrInvalidSuite)
+
+	_, err = ReadSuiteText("my-dialect", iotest.ErrReader(os.ErrClosed))
+	require.ErrorIs(t, err, os.ErrClosed)
+	assert.Contains(t, err.Error(), "my-dialect: failed to read spec text")
+}
+
+func TestLoadSuiteFile_spec_text(t *testing.T) {
+	t.Parallel()
+
+	dirTemp := t.TempDir()
+
+	for _, nameFile := range []string{"spec.txt", "spec.md", "SPEC.TXT"} {
+		pathFile := filepath.Join(dirTemp, nameFile)
+		require.NoError(t, os.WriteFile(pathFile, []byte(dialectText), 0o600))
+
+		suite, err := LoadSuiteFile(pathFile)
+		require.NoError(t, err, nameFile)
+
+		assert.Equal(t, strings.TrimSuffix(nameFile, filepath.Ext(nameFile)), suite.Name)
+		assert.Len(t, suite.Cases(), 3, nameFile)
+	}
+
+	fsys := fstest.MapFS{"testdata/gfm.txt": {Data: []byte(dialectText)}}
+
+	suite, err := LoadSuiteFS(fsys, "testdata/gfm.txt")
+	require.NoError(t, err)
+
+	assert.Equal(t, "gfm", suite.Name)
+	assert.Equal(t, ExtensionList("table strikethrough"), suite.Cases()[1].Extensions)
+}
@@ -0,0 +1,201 @@
+package mdspec
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Suite is a set of test cases in the same format as the embedded spec files.
+// It can hold the test cases of a spec newer than this module, of a forked spec
+// or of the examples of your own Markdown dialect. Give it to WithSuite to run
+// them instead of the embedded ones.
+type Suite struct {
+	// Name identifies the suite. It is used as the version of the report and
+	// of the baseline.
+	Name string
+	// testCases holds the validated test cases in the given order.
+	testCases []TestCase
+}
+
+// NewSuite returns a suite of the given test cases. The test cases are copied.
+//
+// It returns an error wrapping ErrInvalidSuite if there are no test cases, an
+// example number is not positive or is used more than once.
+func NewSuite(name string, testCases []TestCase) (*Suite, error) {
+	if len(testCases) == 0 {
+		return nil, errors.Wrapf(ErrInvalidSuite, "%s: no test cases", name)
+	}
+
+	seen := make(map[int]bool, len(testCases))
+
+	for index, testCase := range testCases {
+		if testCase.ExampleNum <= 0 {
+			return nil, errors.Wrapf(ErrInvalidSuite,
+				"%s: test case #%d: example number must be positive, got %d", name, index+1, testCase.ExampleNum)
+		}
+
+		if seen[testCase.ExampleNum] {
+			return nil, errors.Wrapf(ErrInvalidSuite,
+				"%s: test case #%d: duplicate example number %d", name, index+1, testCase.ExampleNum)
+		}
+
+		seen[testCase.ExampleNum] = true
+	}
+
+	return &Suite{Name: name, testCases: slices.Clone(testCases)}, nil
+}
+
+// LoadSuite returns the suite of the embedded test cases of the spec version.
+// "latest" and version constraints are resolved, see ResolveVersion, and the
+// name of the suite is the resolved version.
+//
+// The test cases are decoded once per version and shared by the suites of all
+// the callers without copying. They are never modified, so the suite is safe for
+// concurrent use and can be given to WithSuite repeatedly, such as in benchmark
+// loops and fuzz harnesses, to measure the function rather than the loading of
+// the test cases. Each call returns a new *Suite, so changing its Name does not
+// affect the other callers.
+//
+// Usage:
+//
+//	suite, err := mdspec.LoadSuite("v0.31.2")
+//	if err != nil {
+//		b.Fatal(err)
+//	}
+//
+//	for b.Loop() {
+//		_ = mdspec.SpecCheck("", myFunc, mdspec.WithSuite(suite))
+//	}
+func LoadSuite(specVersion string) (*Suite, error) {
+	resolvedVer, err := resolveVersion(specVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, ok := embeddedSuites.Load(resolvedVer); ok {
+		testCases, _ := cached.([]TestCase)
+
+		return &Suite{Name: resolvedVer, testCases: testCases}, nil
+	}
+
+	testCases, err := cachedTestCases(resolvedVer)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, _ := embeddedSuites.LoadOrStore(resolvedVer, testCases)
+	testCases, _ = cached.([]TestCase)
+
+	return &Suite{Name: resolvedVer, testCases: testCases}, nil
+}
+
+// ReadSuite reads a suite from "r" in the JSON format of the embedded spec files,
+// which is the output of "spec_tests.py --dump-tests" of the CommonMark spec
+// repository. E.g.
+//
+//	[
+//	  {
+//	    "markdown": "\tfoo\tbaz\t\tbim\n",
+//	    "html": "<pre><code>foo\tbaz\t\tbim\n</code></pre>\n",
+//	    "example": 1,
+//	    "start_line": 355,
+//	    "end_line": 360,
+//	    "section": "Tabs"
+//	  }
+//	]
+func ReadSuite(name string, r io.Reader) (*Suite, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: failed to read suite", name)
+	}
+
+	var testCases []TestCase
+
+	if err := jsonUnmarshal(data, &testCases); err != nil {
+		return nil, errors.Wrapf(ErrInvalidSuite, "%s: %v", name, err)
+	}
+
+	return NewSuite(name, testCases)
+}
+
+// LoadSuiteFile reads a suite from the JSON file. See ReadSuite for the format.
+// Files with the ".txt" or ".md" extension are read as spec.txt. See
+// ReadSuiteText for the format. The name of the suite is the file name without
+// the extension, such as "spec_v0.32" for "testdata/spec_v0.32.json".
+//
+// Usage:
+//
+//	suite, err := mdspec.LoadSuiteFile("testdata/spec_v0.32.json")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	err = mdspec.SpecCheck("", myFunc, mdspec.WithSuite(suite))
+func LoadSuiteFile(pathFile string) (*Suite, error) {
+	file, err := os.Open(pathFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open suite file")
+	}
+
+	defer file.Close()
+
+	return readSuiteFile(pathFile, file)
+}
+
+// LoadSuiteFS reads a suite from the file in the file system, such as an
+// embed.FS. See LoadSuiteFile for the format and the name of the suite.
+func LoadSuiteFS(fsys fs.FS, pathFile string) (*Suite, error) {
+	file, err := fsys.Open(pathFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open suite file")
+	}
+
+	defer file.Close()
+
+	return readSuiteFile(pathFile, file)
+}
+
+// WithSuite runs the test cases of the suite instead of the embedded ones. The
+// spec version given to SpecCheck, RunSpec, RunT and their variants is then
+// ignored and the name of the suite is used as the version of the report.
+//
+// The other options, such as filters, known failures, baselines and thresholds,
+// apply to the suite as they do to the embedded test cases.
+func WithSuite(suite *Suite) Option {
+	return func(conf *config) {
+		conf.suite = suite
+	}
+}
+
+// Cases returns a copy of the test cases of the suite.
+func (s *Suite) Cases() []TestCase {
+	return slices.Clone(s.testCases)
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// readSuiteFile reads a suite from the opened file in the format of its
+// extension.
+func readSuiteFile(pathFile string, r io.Reader) (*Suite, error) {
+	switch strings.ToLower(filepath.Ext(pathFile)) {
+	case ".txt", ".md":
+		return ReadSuiteText(nameSuite(pathFile), r)
+	default:
+		return ReadSuite(nameSuite(pathFile), r)
+	}
+}
+
+// nameSuite returns the name of the suite loaded from the file path.
+func nameSuite(pathFile string) string {
+	name := filepath.Base(filepath.FromSlash(pathFile))
+
+	return name[:len(name)-len(filepath.Ext(name))]
+}
@@ -0,0 +1,298 @@
+package mdspec
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialectJSON is a suite of a custom Markdown dialect for testing.
+const dialectJSON = `[
+  {"markdown": "==mark==\n", "html": "<p><mark>mark</mark></p>\n", "example": 1, "section": "Highlight"},
+  {"markdown": "~~del~~\n", "html": "<p><del>del</del></p>\n", "example": 2, "section": "Strikethrough"},
+  {"markdown": "~del~\n", "html": "<p><del>del</del></p>\n", "example": 3, "section": "Strikethrough"}
+]`
+
+// ----------------------------------------------------------------------------
+//  NewSuite()
+// ----------------------------------------------------------------------------
+
+func TestNewSuite(t *testing.T) {
+	t.Parallel()
+
+	testCases := []TestCase{
+		{Markdown: "a\n", HTML: "<p>a</p>\n", ExampleNum: 2},
+		{Markdown: "b\n", HTML: "<p>b</p>\n", ExampleNum: 1},
+	}
+
+	suite, err := NewSuite("my-dialect", testCases)
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-dialect", suite.Name)
+	assert.Equal(t, testCases, suite.Cases(), "the order should be kept")
+
+	// Defensive copies
+	testCases[0].HTML = "corrupted"
+	suite.Cases()[1].HTML = "corrupted"
+
+	assert.Equal(t, "<p>a</p>\n", suite.Cases()[0].HTML)
+	assert.Equal(t, "<p>b</p>\n", suite.Cases()[1].HTML)
+}
+
+func TestNewSuite_invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name      string
+		expectErr string
+		testCases []TestCase
+	}{
+		{name: "no test cases", testCases: nil, expectErr: "no test cases"},
+		{
+			name:      "zero example number",
+			testCases: []TestCase{{ExampleNum: 1}, {ExampleNum: 0}},
+			expectErr: "test case #2: example number must be positive, got 0",
+		},
+		{
+			name:      "duplicate example number",
+			testCases: []TestCase{{ExampleNum: 1}, {ExampleNum: 2}, {ExampleNum: 1}},
+			expectErr: "test case #3: duplicate example number 1",
+		},
+	} {
+		_, err := NewSuite("my-dialect", test.testCases)
+
+		require.ErrorIs(t, err, ErrInvalidSuite, test.name)
+		assert.Contains(t, err.Error(), "my-dialect: "+test.expectErr, test.name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  LoadSuite()
+// ----------------------------------------------------------------------------
+
+func TestLoadSuite(t *testing.T) {
+	t.Parallel()
+
+	suite, err := LoadSuite("v0.31.x")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.31.2", suite.Name, "the name should be the resolved version")
+
+	expected, err := Cases("v0.31.2")
+	require.NoError(t, err)
+	assert.Equal(t, expected, suite.Cases())
+
+	sameSuite, err := LoadSuite("latest")
+	require.NoError(t, err)
+	assert.NotSame(t, suite, sameSuite, "each caller should get its own suite")
+	assert.Same(t, &suite.testCases[0], &sameSuite.testCases[0], "the test cases should be decoded once per version")
+
+	report, err := RunSpec("", getGoldenParser(t, "v0.31.2"), WithSuite(suite), WithExampleRange(1, 20))
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.31.2", report.Version)
+	assert.Equal(t, 20, report.Passed)
+}
+
+func TestLoadSuite_name_not_shared(t *testing.T) {
+	t.Parallel()
+
+	suite, err := LoadSuite("v0.30")
+	require.NoError(t, err)
+
+	suite.Name = "hijacked"
+
+	report, err := RunSpec("v0.30", getGoldenParser(t, "v0.30"), WithExampleRange(1, 5))
+	require.NoError(t, err)
+	assert.Equal(t, "v0.30", report.Version, "renaming a loaded suite should not affect the others")
+
+	otherSuite, err := LoadSuite("v0.30")
+	require.NoError(t, err)
+	assert.Equal(t, "v0.30", otherSuite.Name)
+}
+
+func TestLoadSuite_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := LoadSuite("unknown")
+	require.ErrorIs(t, err, ErrInvalidVersion)
+
+	_, err = LoadSuite("v0.1")
+	require.ErrorIs(t, err, ErrSpecNotFound)
+}
+
+// ----------------------------------------------------------------------------
+//  ReadSuite(), LoadSuiteFile() and LoadSuiteFS()
+// ----------------------------------------------------------------------------
+
+func TestReadSuite(t *testing.T) {
+	t.Parallel()
+
+	suite, err := ReadSuite("my-dialect", strings.NewReader(dialectJSON))
+	require.NoError(t, err)
+
+	require.Len(t, suite.Cases(), 3)
+	assert.Equal(t, TestCase{
+		Markdown:   "~~del~~\n",
+		HTML:       "<p><del>del</del></p>\n",
+		Section:    "Strikethrough",
+		ExampleNum: 2,
+	}, suite.Cases()[1])
+}
+
+func TestReadSuite_extensions(t *testing.T) {
+	t.Parallel()
+
+	// The output of "spec_tests.py --dump-tests" of cmark-gfm
+	const gfmJSON = `[
+  {"markdown": "a\n", "html": "<p>a</p>\n", "example": 1, "start_line": 1, "end_line": 5,
+   "section": "Tabs", "extensions": []},
+  {"markdown": "www.a.b ~~c~~\n", "html": "<p>x</p>\n", "example": 2, "start_line": 7, "end_line": 11,
+   "section": "Autolinks (extension)", "extensions": ["autolink", "strikethrough"]}
+]`
+
+	suite, err := ReadSuite("gfm", strings.NewReader(gfmJSON))
+	require.NoError(t, err)
+
+	testCases := suite.Cases()
+	assert.Equal(t, ExtensionList(""), testCases[0].Extensions)
+	assert.Equal(t, ExtensionList("autolink strikethrough"), testCases[1].Extensions)
+	assert.Equal(t, []string{"autolink", "strikethrough"}, testCases[1].Extensions.Names())
+
+	// It is written back as an array
+	data, err := json.Marshal(testCases)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"extensions":["autolink","strikethrough"]`)
+	assert.Equal(t, 1, strings.Count(string(data), `"extensions"`), "empty extensions should be omitted")
+
+	var decoded []TestCase
+
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, testCases, decoded)
+
+	// A space-separated string is also accepted
+	require.NoError(t, json.Unmarshal([]byte(`[{"extensions": "table  tasklist"}]`), &decoded))
+	assert.Equal(t, ExtensionList("table tasklist"), decoded[0].Extensions)
+
+	_, err = ReadSuite("gfm", strings.NewReader(`[{"example": 1, "extensions": [1]}]`))
+	require.ErrorIs(t, err, ErrInvalidSuite)
+	assert.Contains(t, err.Error(), "extensions should be an array of strings")
+}
+
+func TestReadSuite_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadSuite("my-dialect", iotest.ErrReader(os.ErrClosed))
+	require.ErrorIs(t, err, os.ErrClosed)
+	assert.Contains(t, err.Error(), "my-dialect: failed to read suite")
+
+	_, err = ReadSuite("my-dialect", strings.NewReader(`{"markdown": "a"}`))
+	require.ErrorIs(t, err, ErrInvalidSuite, "it should be an array")
+
+	_, err = ReadSuite("my-dialect", strings.NewReader(`[]`))
+	require.ErrorIs(t, err, ErrInvalidSuite, "it should not be empty")
+}
+
+func TestLoadSuiteFile(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "spec_v0.32.json")
+	require.NoError(t, os.WriteFile(pathFile, []byte(dialectJSON), 0o600))
+
+	suite, err := LoadSuiteFile(pathFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, "spec_v0.32", suite.Name, "the name should be the file name without extension")
+	assert.Len(t, suite.Cases(), 3)
+
+	_, err = LoadSuiteFile(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadSuiteFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{"testdata/dialect.json": {Data: []byte(dialectJSON)}}
+
+	suite, err := LoadSuiteFS(fsys, "testdata/dialect.json")
+	require.NoError(t, err)
+
+	assert.Equal(t, "dialect", suite.Name)
+	assert.Len(t, suite.Cases(), 3)
+
+	_, err = LoadSuiteFS(fsys, "testdata/missing.json")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// Same as the embedded one
+	suite, err = LoadSuiteFS(os.DirFS(nameDirSpecs), "spec_v0.31.2.json")
+	require.NoError(t, err)
+
+	expected, err := Cases("v0.31.2")
+	require.NoError(t, err)
+	assert.Equal(t, expected, suite.Cases())
+}
+
+// ----------------------------------------------------------------------------
+//  WithSuite()
+// ----------------------------------------------------------------------------
+
+func TestWithSuite(t *testing.T) {
+	t.Parallel()
+
+	suite, err := ReadSuite("my-dialect", strings.NewReader(dialectJSON))
+	require.NoError(t, err)
+
+	// Supports "==" and "~~" but not single "~"
+	myFunc := func(markdown string) (string, error) {
+		switch markdown {
+		case "==mark==\n":
+			return "<p><mark>mark</mark></p>\n", nil
+		case "~~del~~\n":
+			return "<p><del>del</del></p>\n", nil
+		}
+
+		return "<p>" + markdown + "</p>\n", nil
+	}
+
+	require.NoError(t, SpecCheck("", myFunc, WithSuite(suite), WithExamples(1, 2)))
+	require.NoError(t, SpecCheck("v0.13", myFunc, WithSuite(suite), WithKnownFailures(3)),
+		"the spec version should be ignored")
+
+	err = SpecCheck("", myFunc, WithSuite(suite))
+
+	var mismatchErr *MismatchError
+
+	require.ErrorAs(t, err, &mismatchErr)
+	assert.Equal(t, 3, mismatchErr.TestCase.ExampleNum)
+
+	report, err := RunSpec("", myFunc, WithSuite(suite))
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-dialect", report.Version, "the suite name should be the version")
+	assert.Equal(t, 2, report.Passed)
+	assert.Equal(t, []string{"Highlight", "Strikethrough"}, []string{report.Sections()[0].Name, report.Sections()[1].Name})
+
+	// Baselines are checked against the suite name
+	require.NoError(t, SpecCheck("", myFunc, WithSuite(suite), WithBaseline(report.Baseline())))
+
+	_, err = RunSpec("", myFunc, WithSuite(suite), WithBaseline(&Baseline{Version: "v0.31.2"}))
+	require.ErrorIs(t, err, ErrBaselineVersion)
+
+	// Thresholds
+	require.NoError(t, SpecCheck("", myFunc, WithSuite(suite), WithThresholds(Threshold{MinPassRate: 66})))
+
+	// Filters
+	_, err = RunSpec("", myFunc, WithSuite(suite), WithSections("Tabs"))
+	require.ErrorIs(t, err, ErrNoTestCases)
+	assert.Contains(t, err.Error(), "spec my-dialect")
+
+	RunT(t, "", myFunc, WithSuite(suite), WithSections("Highlight"))
+}
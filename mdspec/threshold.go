@@ -0,0 +1,94 @@
+package mdspec
+
+import "fmt"
+
+// Threshold is the minimum pass rate required for all the test cases run or for
+// a spec section.
+type Threshold struct {
+	// Section is the name of the spec section. E.g. "Tabs". If empty, the
+	// threshold applies to all the test cases run.
+	Section string
+	// MinPassRate is the minimum pass rate in percent. E.g. 95 for 95%.
+	MinPassRate float64
+}
+
+// ThresholdMiss is a threshold that was not met and the actual pass rate.
+type ThresholdMiss struct {
+	Threshold
+
+	// PassRate is the actual pass rate in percent.
+	PassRate float64
+	// Passed is the number of test cases that passed.
+	Passed int
+	// Total is the number of test cases run. It is zero if no test case of the
+	// section was run, such as a misspelled or filtered out section.
+	Total int
+}
+
+// String returns the description of the miss. E.g.
+// "Tabs: 90.9% (10/11) < 100.0%".
+func (m ThresholdMiss) String() string {
+	name := m.Section
+	if name == "" {
+		name = "overall"
+	}
+
+	if m.Total == 0 {
+		return fmt.Sprintf("%s: no test cases run, want >= %.1f%%", name, m.MinPassRate)
+	}
+
+	return fmt.Sprintf("%s: %.1f%% (%d/%d) < %.1f%%", name, m.PassRate, m.Passed, m.Total, m.MinPassRate)
+}
+
+// WithThresholds enables the threshold mode, in which the run succeeds if the
+// pass rates meet all the given thresholds even if some test cases fail. The
+// pass rates are computed on the test cases selected by the other options, and
+// the failures of the individual test cases are not reported.
+//
+// If any threshold is not met, SpecCheck returns a *ThresholdError that lists
+// all the missed thresholds, and RunT fails the test with it. RunSpec is not
+// affected. Use (*Report).CheckThresholds for its report.
+//
+// Usage:
+//
+//	// Overall >= 95% and Tabs = 100%
+//	err := mdspec.SpecCheck("latest", myFunc, mdspec.WithThresholds(
+//		mdspec.Threshold{MinPassRate: 95},
+//		mdspec.Threshold{Section: "Tabs", MinPassRate: 100},
+//	))
+func WithThresholds(thresholds ...Threshold) Option {
+	return func(conf *config) {
+		conf.thresholds = append(conf.thresholds, thresholds...)
+	}
+}
+
+// CheckThresholds returns a *ThresholdError if the pass rates of the report do
+// not meet the given thresholds. See Threshold for the meaning of each.
+func (r *Report) CheckThresholds(thresholds ...Threshold) error {
+	sections := map[string]SectionResult{}
+	for _, section := range r.Sections() {
+		sections[section.Name] = section
+	}
+
+	misses := []ThresholdMiss{}
+
+	for _, threshold := range thresholds {
+		miss := ThresholdMiss{Threshold: threshold, Passed: r.Passed, Total: r.Total}
+		if threshold.Section != "" {
+			section := sections[threshold.Section]
+			miss.Passed, miss.Total = section.Passed, section.Total
+		}
+
+		miss.PassRate = percentage(miss.Passed, miss.Total)
+
+		if miss.Total == 0 || miss.PassRate < threshold.MinPassRate {
+			misses = append(misses, miss)
+		}
+	}
+
+	if len(misses) > 0 {
+		return &ThresholdError{Misses: misses}
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package mdspec
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  (*Report).CheckThresholds()
+// ----------------------------------------------------------------------------
+
+func TestReport_CheckThresholds(t *testing.T) {
+	t.Parallel()
+
+	// Tabs: 10/11, Backslash escapes: 0/1, overall: 10/12
+	report, err := RunSpec("v0.31.2", passOnly(t, makeRange(1, 10)...), WithExampleRange(1, 12))
+	require.NoError(t, err)
+
+	require.NoError(t, report.CheckThresholds(), "no thresholds should always pass")
+	require.NoError(t, report.CheckThresholds(
+		Threshold{MinPassRate: 80},
+		Threshold{Section: "Tabs", MinPassRate: 90},
+		Threshold{Section: "Backslash escapes", MinPassRate: 0},
+	))
+
+	err = report.CheckThresholds(
+		Threshold{MinPassRate: 95},
+		Threshold{Section: "Tabs", MinPassRate: 90},
+		Threshold{Section: "Tabs", MinPassRate: 100},
+		Threshold{Section: "Tab", MinPassRate: 50},
+	)
+
+	var thresholdErr *ThresholdError
+
+	require.ErrorAs(t, err, &thresholdErr)
+	require.Len(t, thresholdErr.Misses, 3, "all the missed thresholds should be reported")
+
+	assert.Equal(t, ThresholdMiss{
+		Threshold: Threshold{MinPassRate: 95},
+		PassRate:  percentage(10, 12),
+		Passed:    10,
+		Total:     12,
+	}, thresholdErr.Misses[0])
+	assert.Equal(t, 0, thresholdErr.Misses[2].Total, "unknown section should be a miss")
+
+	assert.Equal(t, ""+
+		"3 pass rate threshold(s) not met:\n"+
+		"  overall: 83.3% (10/12) < 95.0%\n"+
+		"  Tabs: 90.9% (10/11) < 100.0%\n"+
+		"  Tab: no test cases run, want >= 50.0%",
+		err.Error())
+}
+
+// ----------------------------------------------------------------------------
+//  WithThresholds()
+// ----------------------------------------------------------------------------
+
+func TestSpecCheck_with_thresholds(t *testing.T) {
+	t.Parallel()
+
+	myFunc := passOnly(t, makeRange(1, 10)...)
+
+	for _, maxConcurrency := range []int{-1, 0} {
+		err := SpecCheck("v0.31.2", myFunc,
+			WithSections("Tabs"),
+			WithConcurrency(maxConcurrency),
+			WithThresholds(Threshold{MinPassRate: 90}),
+		)
+		require.NoError(t, err, "failing examples should be tolerated if the thresholds are met")
+
+		err = SpecCheck("v0.31.2", myFunc,
+			WithSections("Tabs"),
+			WithConcurrency(maxConcurrency),
+			WithThresholds(Threshold{MinPassRate: 90}),
+			WithThresholds(Threshold{Section: "Tabs", MinPassRate: 100}),
+		)
+
+		var thresholdErr *ThresholdError
+
+		require.ErrorAs(t, err, &thresholdErr)
+		require.Len(t, thresholdErr.Misses, 1)
+		assert.Equal(t, "Tabs", thresholdErr.Misses[0].Section)
+	}
+}
+
+func TestSpecCheck_with_thresholds_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	err := SpecCheckContext(ctx, "v0.31.2", passOnly(t), WithThresholds(Threshold{MinPassRate: 0}))
+
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRunT_with_thresholds(t *testing.T) {
+	t.Parallel()
+
+	RunT(t, "v0.31.2", passOnly(t, makeRange(1, 10)...),
+		WithExampleRange(1, 12),
+		WithThresholds(Threshold{MinPassRate: 80}),
+	)
+}
+
+func TestRunT_with_thresholds_failure(t *testing.T) {
+	t.Parallel()
+
+	//nolint:gosec // the command is the test binary itself
+	cmd := exec.CommandContext(t.Context(), os.Args[0],
+		"-test.run", "^TestRunT_thresholds_helper_process$", "-test.v")
+	cmd.Env = append(os.Environ(), envRunTHelper+"=1")
+
+	out, err := cmd.CombinedOutput()
+	require.Error(t, err, "the helper process should fail")
+
+	output := string(out)
+
+	assert.Contains(t, output, "--- FAIL: TestRunT_thresholds_helper_process ")
+	assert.Contains(t, output, "Tabs: 90.9% (10/11) < 100.0%")
+	assert.Contains(t, output, "tolerated in the threshold mode: example 11 fail")
+	assert.NotContains(t, output, "--- FAIL: TestRunT_thresholds_helper_process/Tabs/example_11",
+		"failing examples should not fail by themselves")
+}
+
+func TestRunT_with_thresholds_filtered(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		run        string
+		expectFail bool
+	}{
+		{run: "Backslash_escapes", expectFail: false},
+		{run: "Backslash_escapes/example_12", expectFail: false},
+		{run: "Unknown_section", expectFail: false},
+		{run: "Tabs", expectFail: true},
+	} {
+		//nolint:gosec // the command is the test binary itself
+		cmd := exec.CommandContext(t.Context(), os.Args[0],
+			"-test.run", "^TestRunT_thresholds_filtered_helper_process$/"+test.run, "-test.v")
+		cmd.Env = append(os.Environ(), envRunTHelper+"=1")
+
+		out, err := cmd.CombinedOutput()
+		output := string(out)
+
+		assert.NotContains(t, output, "no test cases run",
+			"thresholds of the sections filtered out by -run should be ignored. -run %s", test.run)
+
+		if !test.expectFail {
+			require.NoError(t, err, "-run %s\n%s", test.run, output)
+
+			continue
+		}
+
+		require.Error(t, err, "-run %s", test.run)
+		assert.Contains(t, output, "Tabs: 90.9% (10/11) < 100.0%")
+		assert.NotContains(t, output, "Backslash escapes:")
+	}
+}
+
+// TestRunT_thresholds_filtered_helper_process is not a real test. It passes the
+// Backslash escapes section but not the Tabs section, and is used as a helper
+// process of TestRunT_with_thresholds_filtered to select sections with "-run".
+func TestRunT_thresholds_filtered_helper_process(t *testing.T) {
+	t.Parallel()
+
+	if os.Getenv(envRunTHelper) != "1" {
+		t.Skip("helper process for TestRunT_with_thresholds_filtered")
+	}
+
+	RunT(t, "v0.31.2", passOnly(t, append(makeRange(1, 10), makeRange(12, 24)...)...),
+		WithExampleRange(1, 24),
+		WithThresholds(
+			Threshold{MinPassRate: 100},
+			Threshold{Section: "Tabs", MinPassRate: 100},
+			Threshold{Section: "Backslash escapes", MinPassRate: 100},
+		),
+	)
+}
+
+// TestRunT_thresholds_helper_process is not a real test. It misses the threshold
+// of the Tabs section and is used as a helper process of
+// TestRunT_with_thresholds_failure.
+func TestRunT_thresholds_helper_process(t *testing.T) {
+	t.Parallel()
+
+	if os.Getenv(envRunTHelper) != "1" {
+		t.Skip("helper process for TestRunT_with_thresholds_failure")
+	}
+
+	RunT(t, "v0.31.2", passOnly(t, makeRange(1, 10)...),
+		WithSections("Tabs"),
+		WithThresholds(Threshold{Section: "Tabs", MinPassRate: 100}),
+	)
+}
@@ -0,0 +1,284 @@
+package mdspec
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+)
+
+// CompareVersions compares two spec versions by semantic versioning. The result
+// is 0 if a == b, -1 if a < b and +1 if a > b. E.g. "v0.9" < "v0.10" < "v1.0".
+//
+// An invalid version is less than any valid one and equal to the other invalid
+// ones.
+func CompareVersions(a, b string) int {
+	return semver.Compare(a, b)
+}
+
+// SpecInfo is the metadata of an embedded version of the CommonMark spec.
+type SpecInfo struct {
+	// Version is the spec version such as "v0.31.2".
+	Version string `json:"version"`
+	// URL is the URL of the official spec.json of the version.
+	URL string `json:"url"`
+	// Date is the enactment date of the version in "YYYY-MM-DD" format.
+	Date string `json:"date"`
+}
+
+// String returns the name of the spec version with its date. E.g.
+// "CommonMark 0.31.2 (2024-01-28)".
+func (s SpecInfo) String() string {
+	return fmt.Sprintf("CommonMark %s (%s)", strings.TrimPrefix(s.Version, "v"), s.Date)
+}
+
+// Versions returns the metadata of all available versions of the specification
+// in ascending order of semantic versioning. The returned slice is a copy.
+//
+// Usage:
+//
+//	specInfos, err := mdspec.Versions()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	for _, specInfo := range specInfos {
+//		fmt.Println(specInfo, specInfo.URL) // CommonMark 0.31.2 (2024-01-28) https://...
+//	}
+func Versions() ([]SpecInfo, error) {
+	specInfos, err := cachedSpecInfos()
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Clone(specInfos), nil
+}
+
+// VersionInfo returns the metadata of the spec version. The version can be
+// "latest" or a constraint. See ResolveVersion.
+//
+// It returns an error wrapping ErrSpecNotFound if the version is not embedded.
+func VersionInfo(specVersion string) (SpecInfo, error) {
+	resolvedVer, err := resolveVersion(specVersion)
+	if err != nil {
+		return SpecInfo{}, err
+	}
+
+	specInfos, err := Versions()
+	if err != nil {
+		return SpecInfo{}, err
+	}
+
+	for _, specInfo := range specInfos {
+		if specInfo.Version == resolvedVer {
+			return specInfo, nil
+		}
+	}
+
+	return SpecInfo{}, errors.Wrapf(ErrSpecNotFound, "%s is not embedded", resolvedVer)
+}
+
+// VersionAt returns the metadata of the latest spec version enacted on or before
+// the given date, that is, the version in effect on the date. Use it to check a
+// renderer against the spec of its era.
+//
+// It returns an error wrapping ErrSpecNotFound if the date is before the
+// enactment of the oldest embedded version.
+//
+// Usage:
+//
+//	// The spec version a renderer released in mid 2020 targets
+//	specInfo, err := mdspec.VersionAt(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	err = mdspec.SpecCheck(specInfo.Version, legacyRenderer) // v0.29
+func VersionAt(date time.Time) (SpecInfo, error) {
+	specInfos, err := Versions()
+	if err != nil {
+		return SpecInfo{}, err
+	}
+
+	day := date.Format(time.DateOnly)
+
+	// The versions are in ascending order and so are their dates
+	for _, specInfo := range slices.Backward(specInfos) {
+		if specInfo.Date <= day {
+			return specInfo, nil
+		}
+	}
+
+	return SpecInfo{}, errors.Wrapf(ErrSpecNotFound, "no spec version was enacted on or before %s", day)
+}
+
+// ResolveVersion returns the latest embedded spec version that satisfies the
+// given constraint. The constraint is one of:
+//
+//   - an exact version such as "v0.30"
+//   - "latest"
+//   - a comparison such as ">=v0.29", ">v0.29", "<=v0.30", "<v0.30" or "=v0.30"
+//   - a wildcard such as "v0.31.x" (any patch of v0.31) or "v0.x"
+//   - space separated constraints that must all be satisfied, such as
+//     ">=v0.29 <v0.31"
+//
+// The constraints are accepted wherever a spec version is given, such as
+// SpecCheck and RunSpec.
+//
+// It returns an error wrapping ErrInvalidVersion for a malformed constraint and
+// ErrSpecNotFound if no embedded spec version satisfies it.
+//
+// Usage:
+//
+//	// Pin to the latest patch of v0.31
+//	version, err := mdspec.ResolveVersion("v0.31.x") // "v0.31.2"
+func ResolveVersion(constraint string) (string, error) {
+	resolvedVer, err := resolveVersion(constraint)
+	if err != nil {
+		return "", err
+	}
+
+	versions, err := ListVersion()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list spec versions")
+	}
+
+	if !slices.Contains(versions, resolvedVer) {
+		return "", errors.Wrapf(ErrSpecNotFound, "%s is not embedded", resolvedVer)
+	}
+
+	return resolvedVer, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// versionConstraint is a condition on a spec version, such as ">=v0.29".
+type versionConstraint func(version string) bool
+
+// readSpecInfos reads and parses the list of the spec versions from the
+// embedded filesystem.
+func readSpecInfos() ([]SpecInfo, error) {
+	jsonList, err := loadFile(nameFileSpecList)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read list of supported spec versions")
+	}
+
+	var specInfos []SpecInfo
+
+	err = jsonUnmarshal(jsonList, &specInfos)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse list of supported spec versions")
+	}
+
+	slices.SortFunc(specInfos, func(a, b SpecInfo) int {
+		return CompareVersions(a.Version, b.Version)
+	})
+
+	return specInfos, nil
+}
+
+// latestMatchingVersion returns the latest embedded CommonMark spec version that
+// satisfies the constraint.
+func latestMatchingVersion(constraint string) (string, error) {
+	constraints, err := parseConstraints(constraint)
+	if err != nil {
+		return "", errors.Wrapf(ErrInvalidVersion,
+			"%q, it should be like 'v0.14', 'v0.31.x' or '>=v0.29': %v", constraint, err)
+	}
+
+	versions, err := ListVersion()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list spec versions")
+	}
+
+	for _, version := range slices.Backward(versions) {
+		if satisfiesAll(version, constraints) {
+			return version, nil
+		}
+	}
+
+	return "", errors.Wrapf(ErrSpecNotFound, "no embedded spec version matches %q", constraint)
+}
+
+// parseConstraint parses a single constraint such as ">=v0.29" or "v0.31.x".
+func parseConstraint(field string) (versionConstraint, error) {
+	if semver.IsValid(field) {
+		return func(version string) bool { return semver.Compare(version, field) == 0 }, nil
+	}
+
+	if base, ok := strings.CutSuffix(field, ".x"); ok && semver.IsValid(base) {
+		switch strings.Count(base, ".") {
+		case 0: // e.g. "v0.x"
+			return func(version string) bool { return semver.Major(version) == base }, nil
+		case 1: // e.g. "v0.31.x"
+			return func(version string) bool { return semver.MajorMinor(version) == base }, nil
+		}
+	}
+
+	for _, operator := range []string{">=", "<=", ">", "<", "="} {
+		operand, ok := strings.CutPrefix(field, operator)
+		if !ok || !semver.IsValid(operand) {
+			continue
+		}
+
+		return func(version string) bool {
+			return satisfiesOperator(operator, semver.Compare(version, operand))
+		}, nil
+	}
+
+	return nil, errors.Errorf("malformed constraint %q", field)
+}
+
+// parseConstraints parses the space separated constraints.
+func parseConstraints(constraint string) ([]versionConstraint, error) {
+	fields := strings.Fields(constraint)
+	if len(fields) == 0 {
+		return nil, errors.New("empty constraint")
+	}
+
+	constraints := make([]versionConstraint, 0, len(fields))
+
+	for _, field := range fields {
+		parsed, err := parseConstraint(field)
+		if err != nil {
+			return nil, err
+		}
+
+		constraints = append(constraints, parsed)
+	}
+
+	return constraints, nil
+}
+
+// satisfiesAll returns true if the version satisfies all the constraints.
+func satisfiesAll(version string, constraints []versionConstraint) bool {
+	for _, constraint := range constraints {
+		if !constraint(version) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// satisfiesOperator returns true if the result of semver.Compare satisfies the
+// comparison operator.
+func satisfiesOperator(operator string, cmp int) bool {
+	switch operator {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	default: // "="
+		return cmp == 0
+	}
+}
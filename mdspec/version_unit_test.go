@@ -0,0 +1,241 @@
+package mdspec
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  CompareVersions()
+// ----------------------------------------------------------------------------
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "v0.30", b: "v0.30", expected: 0},
+		{a: "v0.30", b: "v0.30.0", expected: 0},
+		{a: "v0.9", b: "v0.10", expected: -1},
+		{a: "v0.100", b: "v0.99", expected: 1},
+		{a: "v1.0", b: "v0.31.2", expected: 1},
+		{a: "v0.31.2", b: "v0.31.10", expected: -1},
+		{a: "unknown", b: "v0.13", expected: -1},
+		{a: "unknown", b: "invalid", expected: 0},
+	} {
+		assert.Equal(t, test.expected, CompareVersions(test.a, test.b), "%s vs %s", test.a, test.b)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Versions(), VersionInfo() and VersionAt()
+// ----------------------------------------------------------------------------
+
+func TestVersions(t *testing.T) {
+	t.Parallel()
+
+	specInfos, err := Versions()
+	require.NoError(t, err)
+
+	versions, err := ListVersion()
+	require.NoError(t, err)
+
+	require.Len(t, specInfos, len(versions))
+
+	for index, specInfo := range specInfos {
+		assert.Equal(t, versions[index], specInfo.Version, "it should be in the same order as ListVersion")
+		assert.NotEmpty(t, specInfo.URL, specInfo.Version)
+
+		_, err := time.Parse(time.DateOnly, specInfo.Date)
+		require.NoError(t, err, specInfo.Version)
+	}
+
+	assert.Equal(t, SpecInfo{
+		Version: "v0.13",
+		URL:     "https://spec.commonmark.org/0.13/spec.json",
+		Date:    "2014-12-10",
+	}, specInfos[0])
+}
+
+func TestSpecInfo_String(t *testing.T) {
+	t.Parallel()
+
+	specInfo := SpecInfo{Version: "v0.31.2", Date: "2024-01-28"}
+
+	assert.Equal(t, "CommonMark 0.31.2 (2024-01-28)", specInfo.String())
+}
+
+func TestVersionInfo(t *testing.T) {
+	t.Parallel()
+
+	specInfo, err := VersionInfo("latest")
+	require.NoError(t, err)
+	assert.Equal(t, "v0.31.2", specInfo.Version)
+	assert.Equal(t, "2024-01-28", specInfo.Date)
+
+	specInfo, err = VersionInfo("v0.30")
+	require.NoError(t, err)
+	assert.Equal(t, "https://spec.commonmark.org/0.30/spec.json", specInfo.URL)
+
+	_, err = VersionInfo("v0.99")
+	require.ErrorIs(t, err, ErrSpecNotFound)
+	assert.Contains(t, err.Error(), "v0.99 is not embedded")
+
+	_, err = VersionInfo("unknown")
+	require.ErrorIs(t, err, ErrInvalidVersion)
+}
+
+func TestVersionAt(t *testing.T) {
+	t.Parallel()
+
+	for date, expected := range map[string]string{
+		"2026-01-01": "v0.31.2",
+		"2024-01-28": "v0.31.2",
+		"2024-01-27": "v0.30",
+		"2020-06-01": "v0.29",
+		"2015-01-01": "v0.15",
+		"2014-12-10": "v0.14", // v0.13 and v0.14 were enacted on the same day
+	} {
+		day, err := time.Parse(time.DateOnly, date)
+		require.NoError(t, err)
+
+		specInfo, err := VersionAt(day)
+
+		require.NoError(t, err, date)
+		assert.Equal(t, expected, specInfo.Version, date)
+	}
+
+	_, err := VersionAt(time.Date(2014, time.December, 9, 23, 59, 59, 0, time.UTC))
+	require.ErrorIs(t, err, ErrSpecNotFound)
+	assert.Contains(t, err.Error(), "no spec version was enacted on or before 2014-12-09")
+
+	// The date is in its own location. It is already 2024-01-28 in Tokyo.
+	tokyo := time.FixedZone("JST", 9*60*60)
+	specInfo, err := VersionAt(time.Date(2024, time.January, 28, 8, 0, 0, 0, tokyo))
+	require.NoError(t, err)
+	assert.Equal(t, "v0.31.2", specInfo.Version)
+}
+
+//nolint:paralleltest // do not parallelize due to mocking the embedded files
+func TestVersions_errors(t *testing.T) {
+	mockSpecFS(t, fstest.MapFS{})
+
+	_, err := Versions()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read list of supported spec versions")
+
+	_, err = VersionInfo("v0.30")
+	require.Error(t, err)
+
+	_, err = VersionAt(time.Now())
+	require.Error(t, err)
+}
+
+// ----------------------------------------------------------------------------
+//  ListVersion() and LatestVersion()
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // do not parallelize due to mocking the embedded files
+func TestListVersion_semver_order(t *testing.T) {
+	mockSpecFS(t, fstest.MapFS{
+		"_specs/spec_list.json": {Data: []byte(`[
+			{"version": "v0.99"}, {"version": "v1.0"}, {"version": "v0.100"}, {"version": "v0.31.2"}
+		]`)},
+	})
+
+	versions, err := ListVersion()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.31.2", "v0.99", "v0.100", "v1.0"}, versions)
+
+	latestVer, err := LatestVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0", latestVer)
+
+	resolvedVer, err := ResolveVersion("v0.x")
+	require.NoError(t, err)
+	assert.Equal(t, "v0.100", resolvedVer, "the latest minor should be resolved by semver")
+
+	// Missing spec list
+	mockSpecFS(t, fstest.MapFS{})
+
+	for _, constraint := range []string{"v0.30", ">=v0.29"} {
+		_, err = ResolveVersion(constraint)
+
+		require.Error(t, err, constraint)
+		assert.Contains(t, err.Error(), "failed to list spec versions", constraint)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  ResolveVersion()
+// ----------------------------------------------------------------------------
+
+func TestResolveVersion(t *testing.T) {
+	t.Parallel()
+
+	for constraint, expected := range map[string]string{
+		"v0.28":            "v0.28",
+		"latest":           "v0.31.2",
+		">=v0.29":          "v0.31.2",
+		">v0.29":           "v0.31.2",
+		"<v0.30":           "v0.29",
+		"<=v0.30":          "v0.30",
+		"=v0.30":           "v0.30",
+		"=v0.30.0":         "v0.30",
+		"v0.31.x":          "v0.31.2",
+		"v0.30.x":          "v0.30",
+		"v0.x":             "v0.31.2",
+		">=v0.29 <v0.31":   "v0.30",
+		" >v0.13  <v0.15 ": "v0.14",
+		">=v0.29 v0.30":    "v0.30",
+	} {
+		resolvedVer, err := ResolveVersion(constraint)
+
+		require.NoError(t, err, constraint)
+		assert.Equal(t, expected, resolvedVer, constraint)
+	}
+}
+
+func TestResolveVersion_errors(t *testing.T) {
+	t.Parallel()
+
+	for constraint, expectErr := range map[string]error{
+		"v0.99":          ErrSpecNotFound,
+		">=v1.0":         ErrSpecNotFound,
+		"v1.x":           ErrSpecNotFound,
+		">v0.30 <v0.30":  ErrSpecNotFound,
+		"":               ErrInvalidVersion,
+		"~v0.31":         ErrInvalidVersion,
+		"v0.31.2.x":      ErrInvalidVersion,
+		"v.x":            ErrInvalidVersion,
+		">=0.29":         ErrInvalidVersion,
+		">=v0.29 <=":     ErrInvalidVersion,
+		"gfm-0.29":       ErrInvalidVersion,
+		"0.31.2":         ErrInvalidVersion,
+		"latest v0.31.x": ErrInvalidVersion,
+	} {
+		_, err := ResolveVersion(constraint)
+
+		require.ErrorIs(t, err, expectErr, "%q", constraint)
+	}
+}
+
+func TestRunSpec_version_constraint(t *testing.T) {
+	t.Parallel()
+
+	report, err := RunSpec("v0.30.x", getGoldenParser(t, "v0.30"), WithExamples(1))
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.30", report.Version, "the constraint should be resolved")
+
+	err = SpecCheck(">v0.31.2", getGoldenParser(t, "v0.30"))
+	require.ErrorIs(t, err, ErrSpecNotFound)
+	assert.Contains(t, err.Error(), `no embedded spec version matches ">v0.31.2"`)
+}